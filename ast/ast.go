@@ -1,6 +1,11 @@
 package ast
 
+import "pythia/token"
+
 type Node interface {
 	TokenLiteral() string
 	String() string // 디버깅용도
+	// Pos는 노드를 대표하는 토큰의 위치. 대부분 노드가 시작하는 토큰이지만, 중위·대입·조건 표현식은 연산자,
+	// 호출은 `(`, 인덱스와 슬라이스는 `[`, 메소드 호출과 속성은 `.` 토큰의 위치이고 traceback은 이 위치를 가리킨다
+	Pos() token.Position
 }
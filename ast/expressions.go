@@ -18,6 +18,7 @@ type ExpressionStatement struct {
 
 func (es *ExpressionStatement) statementNode()       {}
 func (es *ExpressionStatement) TokenLiteral() string { return es.Token.Literal }
+func (es *ExpressionStatement) Pos() token.Position  { return es.Token.Pos }
 func (es *ExpressionStatement) String() string {
 	if es.Expression != nil {
 		return es.Expression.String()
@@ -33,6 +34,7 @@ type PrefixExpression struct {
 
 func (pe *PrefixExpression) expressionNode()      {}
 func (pe *PrefixExpression) TokenLiteral() string { return pe.Token.Literal }
+func (pe *PrefixExpression) Pos() token.Position  { return pe.Token.Pos }
 func (pe *PrefixExpression) String() string {
 	var out bytes.Buffer
 
@@ -53,6 +55,7 @@ type InfixExpression struct {
 
 func (ie *InfixExpression) expressionNode()      {}
 func (ie *InfixExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *InfixExpression) Pos() token.Position  { return ie.Token.Pos }
 func (ie *InfixExpression) String() string {
 	var out bytes.Buffer
 
@@ -73,6 +76,7 @@ type CallExpression struct {
 
 func (ce *CallExpression) expressionNode()      {}
 func (ce *CallExpression) TokenLiteral() string { return ce.Token.Literal }
+func (ce *CallExpression) Pos() token.Position  { return ce.Token.Pos }
 func (ce *CallExpression) String() string {
 	var out bytes.Buffer
 
@@ -97,6 +101,7 @@ type IndexExpression struct {
 
 func (ie *IndexExpression) expressionNode()      {}
 func (ie *IndexExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IndexExpression) Pos() token.Position  { return ie.Token.Pos }
 func (ie *IndexExpression) String() string {
 	var out bytes.Buffer
 
@@ -109,6 +114,40 @@ func (ie *IndexExpression) String() string {
 	return out.String()
 }
 
+// SliceExpression is `left[start:end:step]`, omitted bounds are nil
+type SliceExpression struct {
+	Token token.Token
+	Left  Expression
+	Start Expression
+	End   Expression
+	Step  Expression
+}
+
+func (se *SliceExpression) expressionNode()      {}
+func (se *SliceExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SliceExpression) Pos() token.Position  { return se.Token.Pos }
+func (se *SliceExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(se.Left.String())
+	out.WriteString("[")
+	if se.Start != nil {
+		out.WriteString(se.Start.String())
+	}
+	out.WriteString(":")
+	if se.End != nil {
+		out.WriteString(se.End.String())
+	}
+	if se.Step != nil {
+		out.WriteString(":")
+		out.WriteString(se.Step.String())
+	}
+	out.WriteString("])")
+
+	return out.String()
+}
+
 type AssignmentExpression struct {
 	Token    token.Token
 	Left     Expression
@@ -118,6 +157,7 @@ type AssignmentExpression struct {
 
 func (ae *AssignmentExpression) expressionNode()      {}
 func (ae *AssignmentExpression) TokenLiteral() string { return ae.Token.Literal }
+func (ae *AssignmentExpression) Pos() token.Position  { return ae.Token.Pos }
 func (ae *AssignmentExpression) String() string {
 	var out bytes.Buffer
 
@@ -136,6 +176,7 @@ type MethodCallExpression struct {
 
 func (mce *MethodCallExpression) expressionNode()      {}
 func (mce *MethodCallExpression) TokenLiteral() string { return mce.Token.Literal }
+func (mce *MethodCallExpression) Pos() token.Position  { return mce.Token.Pos }
 func (mce *MethodCallExpression) String() string {
 	var out bytes.Buffer
 
@@ -144,3 +185,42 @@ func (mce *MethodCallExpression) String() string {
 
 	return out.String()
 }
+
+// AttributeExpression is `Object.Name` without call, e.g. a binding of module
+type AttributeExpression struct {
+	Token  token.Token // token.DOT
+	Object Expression
+	Name   *Identifier
+}
+
+func (ae *AttributeExpression) expressionNode()      {}
+func (ae *AttributeExpression) TokenLiteral() string { return ae.Token.Literal }
+func (ae *AttributeExpression) Pos() token.Position  { return ae.Token.Pos }
+func (ae *AttributeExpression) String() string {
+	return ae.Object.String() + ae.Token.Literal + ae.Name.String()
+}
+
+// ConditionalExpression is `Condition ? Consequence : Alternative`
+type ConditionalExpression struct {
+	Token       token.Token // token.QUESTION
+	Condition   Expression
+	Consequence Expression
+	Alternative Expression
+}
+
+func (ce *ConditionalExpression) expressionNode()      {}
+func (ce *ConditionalExpression) TokenLiteral() string { return ce.Token.Literal }
+func (ce *ConditionalExpression) Pos() token.Position  { return ce.Token.Pos }
+func (ce *ConditionalExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(ce.Condition.String())
+	out.WriteString(" ? ")
+	out.WriteString(ce.Consequence.String())
+	out.WriteString(" : ")
+	out.WriteString(ce.Alternative.String())
+	out.WriteString(")")
+
+	return out.String()
+}
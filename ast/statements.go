@@ -22,6 +22,13 @@ func (p *Program) TokenLiteral() string {
 		return ""
 	}
 }
+func (p *Program) Pos() token.Position {
+	if len(p.Statements) > 0 {
+		return p.Statements[0].Pos()
+	} else {
+		return token.Position{}
+	}
+}
 func (p *Program) String() string {
 	var out bytes.Buffer
 
@@ -40,6 +47,7 @@ type LetStatement struct {
 
 func (ls *LetStatement) statementNode()       {}
 func (ls *LetStatement) TokenLiteral() string { return ls.Token.Literal }
+func (ls *LetStatement) Pos() token.Position  { return ls.Token.Pos }
 func (ls *LetStatement) String() string {
 	var out bytes.Buffer
 
@@ -57,14 +65,16 @@ func (ls *LetStatement) String() string {
 }
 
 type FunctionStatement struct {
-	Token      token.Token
-	Name       *Identifier
-	Parameters []*Identifier
-	Body       *BlockStatement
+	Token       token.Token
+	Name        *Identifier
+	Parameters  []*Identifier
+	Body        *BlockStatement
+	IsGenerator bool // body has yield
 }
 
 func (fs *FunctionStatement) statementNode()       {}
 func (fs *FunctionStatement) TokenLiteral() string { return fs.Token.Literal }
+func (fs *FunctionStatement) Pos() token.Position  { return fs.Token.Pos }
 func (fs *FunctionStatement) String() string {
 	var out bytes.Buffer
 
@@ -90,6 +100,7 @@ type ReturnStatement struct {
 
 func (rs *ReturnStatement) statementNode()       {}
 func (rs *ReturnStatement) TokenLiteral() string { return rs.Token.Literal }
+func (rs *ReturnStatement) Pos() token.Position  { return rs.Token.Pos }
 func (rs *ReturnStatement) String() string {
 	var out bytes.Buffer
 
@@ -104,6 +115,23 @@ func (rs *ReturnStatement) String() string {
 	return out.String()
 }
 
+// YieldStatement is `yield value`, it makes the function a generator
+type YieldStatement struct {
+	Token token.Token // token.YIELD
+	Value Expression  // nil if it yields null
+}
+
+func (ys *YieldStatement) statementNode()       {}
+func (ys *YieldStatement) TokenLiteral() string { return ys.Token.Literal }
+func (ys *YieldStatement) Pos() token.Position  { return ys.Token.Pos }
+func (ys *YieldStatement) String() string {
+	if ys.Value == nil {
+		return ys.TokenLiteral() + ";"
+	}
+
+	return ys.TokenLiteral() + " " + ys.Value.String() + ";"
+}
+
 type IfStatement struct {
 	Token       token.Token
 	Condition   Expression
@@ -113,6 +141,7 @@ type IfStatement struct {
 
 func (is *IfStatement) statementNode()       {}
 func (is *IfStatement) TokenLiteral() string { return is.Token.Literal }
+func (is *IfStatement) Pos() token.Position  { return is.Token.Pos }
 func (is *IfStatement) String() string {
 	var out bytes.Buffer
 
@@ -136,6 +165,7 @@ type BlockStatement struct {
 
 func (bs *BlockStatement) statementNode()       {}
 func (bs *BlockStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BlockStatement) Pos() token.Position  { return bs.Token.Pos }
 func (bs *BlockStatement) String() string {
 	var out bytes.Buffer
 
@@ -153,10 +183,12 @@ type InstructionStatement struct {
 
 func (is *InstructionStatement) statementNode()       {}
 func (is *InstructionStatement) TokenLiteral() string { return is.Token.Literal }
+func (is *InstructionStatement) Pos() token.Position  { return is.Token.Pos }
 func (is *InstructionStatement) String() string       { return is.Instruction }
 
 type ForStatement struct {
 	Token     token.Token
+	Label     *Identifier // optional label for break, continue
 	Index     *Identifier // variable for setting index
 	Value     *Identifier // variable for each item
 	Container Expression  // variable which will be range over
@@ -165,12 +197,146 @@ type ForStatement struct {
 
 func (fs *ForStatement) statementNode()       {}
 func (fs *ForStatement) TokenLiteral() string { return fs.Token.Literal }
+func (fs *ForStatement) Pos() token.Position  { return fs.Token.Pos }
 func (fs *ForStatement) String() string {
 	var out bytes.Buffer
 
+	if fs.Label != nil {
+		out.WriteString(fs.Label.String() + ": ")
+	}
 	out.WriteString(fs.Token.Literal + " " + fs.Value.String() + " in " + fs.Container.String() + " {")
 	out.WriteString(fs.Body.String())
 	out.WriteString("}")
 
 	return out.String()
 }
+
+// LoopStatement is condition-only, infinite, C-style for-loop and while-loop
+type LoopStatement struct {
+	Token     token.Token // token.FOR or token.WHILE
+	Label     *Identifier // optional label for break, continue
+	Init      Statement   // optional, only for C-style
+	Condition Expression  // optional, infinite loop if nil
+	Post      Expression  // optional, only for C-style
+	Body      *BlockStatement
+}
+
+func (ls *LoopStatement) statementNode()       {}
+func (ls *LoopStatement) TokenLiteral() string { return ls.Token.Literal }
+func (ls *LoopStatement) Pos() token.Position  { return ls.Token.Pos }
+func (ls *LoopStatement) String() string {
+	var out bytes.Buffer
+
+	if ls.Label != nil {
+		out.WriteString(ls.Label.String() + ": ")
+	}
+	out.WriteString(ls.Token.Literal + " ")
+	if ls.Init != nil || ls.Post != nil {
+		if ls.Init != nil {
+			out.WriteString(ls.Init.String())
+		}
+		out.WriteString("; ")
+		if ls.Condition != nil {
+			out.WriteString(ls.Condition.String())
+		}
+		out.WriteString("; ")
+		if ls.Post != nil {
+			out.WriteString(ls.Post.String())
+		}
+		out.WriteString(" ")
+	} else if ls.Condition != nil {
+		out.WriteString(ls.Condition.String() + " ")
+	}
+	out.WriteString("{")
+	out.WriteString(ls.Body.String())
+	out.WriteString("}")
+
+	return out.String()
+}
+
+// BranchStatement is break or continue
+type BranchStatement struct {
+	Token token.Token // token.BREAK or token.CONTINUE
+	Label *Identifier // optional, the loop to break or continue
+}
+
+func (bs *BranchStatement) statementNode()       {}
+func (bs *BranchStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BranchStatement) Pos() token.Position  { return bs.Token.Pos }
+func (bs *BranchStatement) String() string {
+	if bs.Label != nil {
+		return bs.TokenLiteral() + " " + bs.Label.String() + ";"
+	}
+
+	return bs.TokenLiteral() + ";"
+}
+
+// TryStatement is try-catch, try-finally or try-catch-finally
+type TryStatement struct {
+	Token   token.Token // token.TRY
+	Body    *BlockStatement
+	Param   *Identifier     // optional, variable for caught error
+	Catch   *BlockStatement // optional if Finally exists
+	Finally *BlockStatement // optional if Catch exists
+}
+
+func (ts *TryStatement) statementNode()       {}
+func (ts *TryStatement) TokenLiteral() string { return ts.Token.Literal }
+func (ts *TryStatement) Pos() token.Position  { return ts.Token.Pos }
+func (ts *TryStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("try {" + ts.Body.String() + "}")
+	if ts.Catch != nil {
+		out.WriteString(" catch ")
+		if ts.Param != nil {
+			out.WriteString("(" + ts.Param.String() + ") ")
+		}
+		out.WriteString("{" + ts.Catch.String() + "}")
+	}
+	if ts.Finally != nil {
+		out.WriteString(" finally {" + ts.Finally.String() + "}")
+	}
+
+	return out.String()
+}
+
+type ThrowStatement struct {
+	Token token.Token // token.THROW
+	Value Expression
+}
+
+func (ts *ThrowStatement) statementNode()       {}
+func (ts *ThrowStatement) TokenLiteral() string { return ts.Token.Literal }
+func (ts *ThrowStatement) Pos() token.Position  { return ts.Token.Pos }
+func (ts *ThrowStatement) String() string {
+	return ts.TokenLiteral() + " " + ts.Value.String() + ";"
+}
+
+// ImportStatement is `import "path" as name` or `from "path" import name1, name2`
+type ImportStatement struct {
+	Token token.Token // token.IMPORT or token.FROM
+	Path  *StringLiteral
+	Alias *Identifier   // optional, only for import. the module name is used if nil
+	Names []*Identifier // only for from-import
+}
+
+func (is *ImportStatement) statementNode()       {}
+func (is *ImportStatement) TokenLiteral() string { return is.Token.Literal }
+func (is *ImportStatement) Pos() token.Position  { return is.Token.Pos }
+func (is *ImportStatement) String() string {
+	if is.Token.Type == token.FROM {
+		names := []string{}
+		for _, n := range is.Names {
+			names = append(names, n.String())
+		}
+
+		return "from \"" + is.Path.Value + "\" import " + strings.Join(names, ", ") + ";"
+	}
+
+	if is.Alias != nil {
+		return "import \"" + is.Path.Value + "\" as " + is.Alias.String() + ";"
+	}
+
+	return "import \"" + is.Path.Value + "\";"
+}
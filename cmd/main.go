@@ -1,18 +1,43 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	user2 "os/user"
+	"path/filepath"
+	"pythia/evaluator"
 	"pythia/repl"
+	"pythia/runner"
 )
 
 func main() {
+	engine := flag.String("engine", runner.ENGINE_EVAL, "engine which runs programs, eval or vm")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [-engine eval|vm] [script [args...]]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if _, err := runner.NewEngine(*engine); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	// PYTHIA_PATH is a list of directories to find modules in, like PATH
+	if paths := os.Getenv("PYTHIA_PATH"); paths != "" {
+		evaluator.SetSearchPaths(filepath.SplitList(paths)...)
+	}
+
+	if flag.NArg() > 0 {
+		os.Exit(runner.RunFile(flag.Arg(0), flag.Args()[1:], *engine, os.Stderr))
+	}
+
 	user, err := user2.Current()
 	if err != nil {
 		panic(err)
 	}
 	fmt.Printf("Hello %s! This is the Pythia programming language!\n", user.Username)
 	fmt.Printf("Feel free to type in commands\n")
-	repl.Start(os.Stdin, os.Stdout)
+	repl.Start(os.Stdin, os.Stdout, *engine)
 }
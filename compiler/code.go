@@ -0,0 +1,252 @@
+package compiler
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+type Instructions []byte
+
+type Opcode byte
+
+const (
+	OpConstant Opcode = iota
+	OpNil             // Go nil, the result of statements and void functions
+	OpNull
+	OpTrue
+	OpFalse
+	OpPop // pop and keep it as the last popped value
+
+	OpAdd
+	OpSub
+	OpMul
+	OpDiv
+	OpMod
+	OpBitAnd
+	OpBitOr
+	OpBitXor
+	OpShiftLeft
+	OpShiftRight
+	OpEqual
+	OpNotEqual
+	OpLess
+	OpGreater
+	OpLessEqual
+	OpGreaterEqual
+	OpMinus
+	OpBang
+
+	OpJump
+	OpJumpIfFalse      // pop condition
+	OpJumpIfFalseOrPop // keep condition and jump if it is false, otherwise pop it. for &&
+	OpJumpIfTrueOrPop  // keep condition and jump if it is true, otherwise pop it. for ||
+
+	OpGetGlobal
+	OpSetGlobal    // define or update global
+	OpAssignGlobal // update defined global with assignment operator
+	OpGetLocal
+	OpSetLocal
+	OpAssignLocal
+	OpPushScope // enter block scope which has local slots
+	OpPopScope
+
+	OpArray
+	OpHash
+	OpIndex
+	OpSetIndex // assign to index with assignment operator
+	OpSlice
+	OpSetSlice // assign to slice with assignment operator
+	OpAttribute
+
+	OpClosure
+	OpCall
+	OpMethodCall
+	OpReturn
+	OpYield // pass the value to the consumer of generator, and suspend until it is resumed
+
+	OpIterInit // replace container with its new iterator
+	OpIterNext // push next value and index if loop has it, or jump if iterator is exhausted
+
+	OpTry    // push handler which jumps to the operand on error
+	OpPopTry // pop handler
+	OpThrow
+	OpRethrow // raise the error of exception again, for finally block which runs on error
+	OpRaise   // raise error constant
+
+	OpImport
+	OpImportName
+	OpInstruction
+)
+
+type Definition struct {
+	Name          string
+	OperandWidths []int
+}
+
+var definitions = map[Opcode]*Definition{
+	OpConstant: {"OpConstant", []int{2}},
+	OpNil:      {"OpNil", []int{}},
+	OpNull:     {"OpNull", []int{}},
+	OpTrue:     {"OpTrue", []int{}},
+	OpFalse:    {"OpFalse", []int{}},
+	OpPop:      {"OpPop", []int{}},
+
+	OpAdd:          {"OpAdd", []int{}},
+	OpSub:          {"OpSub", []int{}},
+	OpMul:          {"OpMul", []int{}},
+	OpDiv:          {"OpDiv", []int{}},
+	OpMod:          {"OpMod", []int{}},
+	OpBitAnd:       {"OpBitAnd", []int{}},
+	OpBitOr:        {"OpBitOr", []int{}},
+	OpBitXor:       {"OpBitXor", []int{}},
+	OpShiftLeft:    {"OpShiftLeft", []int{}},
+	OpShiftRight:   {"OpShiftRight", []int{}},
+	OpEqual:        {"OpEqual", []int{}},
+	OpNotEqual:     {"OpNotEqual", []int{}},
+	OpLess:         {"OpLess", []int{}},
+	OpGreater:      {"OpGreater", []int{}},
+	OpLessEqual:    {"OpLessEqual", []int{}},
+	OpGreaterEqual: {"OpGreaterEqual", []int{}},
+	OpMinus:        {"OpMinus", []int{}},
+	OpBang:         {"OpBang", []int{}},
+
+	OpJump:             {"OpJump", []int{2}},
+	OpJumpIfFalse:      {"OpJumpIfFalse", []int{2}},
+	OpJumpIfFalseOrPop: {"OpJumpIfFalseOrPop", []int{2}},
+	OpJumpIfTrueOrPop:  {"OpJumpIfTrueOrPop", []int{2}},
+
+	OpGetGlobal:    {"OpGetGlobal", []int{2}},
+	OpSetGlobal:    {"OpSetGlobal", []int{2}},
+	OpAssignGlobal: {"OpAssignGlobal", []int{2, 1}},   // global index, assignment operator
+	OpGetLocal:     {"OpGetLocal", []int{1, 2}},       // scope depth, slot index
+	OpSetLocal:     {"OpSetLocal", []int{1, 2}},       // scope depth, slot index
+	OpAssignLocal:  {"OpAssignLocal", []int{1, 2, 1}}, // scope depth, slot index, assignment operator
+	OpPushScope:    {"OpPushScope", []int{2}},         // number of slots
+	OpPopScope:     {"OpPopScope", []int{}},
+
+	OpArray:     {"OpArray", []int{2}},
+	OpHash:      {"OpHash", []int{2}}, // number of keys and values
+	OpIndex:     {"OpIndex", []int{}},
+	OpSetIndex:  {"OpSetIndex", []int{1}}, // assignment operator
+	OpSlice:     {"OpSlice", []int{}},
+	OpSetSlice:  {"OpSetSlice", []int{1}},  // assignment operator
+	OpAttribute: {"OpAttribute", []int{2}}, // constant of attribute name
+
+	OpClosure:    {"OpClosure", []int{2}},       // constant of compiled function
+	OpCall:       {"OpCall", []int{1}},          // number of arguments
+	OpMethodCall: {"OpMethodCall", []int{2, 1}}, // constant of method name, number of arguments
+	OpReturn:     {"OpReturn", []int{}},
+	OpYield:      {"OpYield", []int{}},
+
+	OpIterInit: {"OpIterInit", []int{}},
+	OpIterNext: {"OpIterNext", []int{2, 1}}, // jump position, 1 if loop has index variable
+
+	OpTry:     {"OpTry", []int{2}},
+	OpPopTry:  {"OpPopTry", []int{}},
+	OpThrow:   {"OpThrow", []int{}},
+	OpRethrow: {"OpRethrow", []int{}},
+	OpRaise:   {"OpRaise", []int{2}},
+
+	OpImport:      {"OpImport", []int{2}},      // constant of module path
+	OpImportName:  {"OpImportName", []int{2}},  // constant of binding name
+	OpInstruction: {"OpInstruction", []int{2}}, // constant of instruction name
+}
+
+// ASSIGN_OPERATORS are operands of OpAssignGlobal, OpAssignLocal, OpSetIndex and OpSetSlice
+var ASSIGN_OPERATORS = []string{"=", "+=", "-=", "*=", "/=", "%="}
+
+func Lookup(op byte) (*Definition, error) {
+	def, ok := definitions[Opcode(op)]
+	if !ok {
+		return nil, fmt.Errorf("opcode %d undefined", op)
+	}
+
+	return def, nil
+}
+
+// Make encodes an instruction, operands are big-endian
+func Make(op Opcode, operands ...int) []byte {
+	def, ok := definitions[op]
+	if !ok {
+		return []byte{}
+	}
+
+	instructionLen := 1
+	for _, w := range def.OperandWidths {
+		instructionLen += w
+	}
+
+	instruction := make([]byte, instructionLen)
+	instruction[0] = byte(op)
+
+	offset := 1
+	for i, o := range operands {
+		width := def.OperandWidths[i]
+		switch width {
+		case 2:
+			binary.BigEndian.PutUint16(instruction[offset:], uint16(o))
+		case 1:
+			instruction[offset] = byte(o)
+		}
+		offset += width
+	}
+
+	return instruction
+}
+
+func ReadOperands(def *Definition, ins Instructions) ([]int, int) {
+	operands := make([]int, len(def.OperandWidths))
+	offset := 0
+
+	for i, width := range def.OperandWidths {
+		switch width {
+		case 2:
+			operands[i] = int(ReadUint16(ins[offset:]))
+		case 1:
+			operands[i] = int(ReadUint8(ins[offset:]))
+		}
+		offset += width
+	}
+
+	return operands, offset
+}
+
+func ReadUint16(ins Instructions) uint16 { return binary.BigEndian.Uint16(ins) }
+func ReadUint8(ins Instructions) uint8   { return uint8(ins[0]) }
+
+// String disassembles instructions, one instruction per line with its offset
+func (ins Instructions) String() string {
+	var out bytes.Buffer
+
+	i := 0
+	for i < len(ins) {
+		def, err := Lookup(ins[i])
+		if err != nil {
+			fmt.Fprintf(&out, "ERROR: %s\n", err)
+			return out.String()
+		}
+
+		operands, read := ReadOperands(def, ins[i+1:])
+
+		fmt.Fprintf(&out, "%04d %s\n", i, fmtInstruction(def, operands))
+
+		i += 1 + read
+	}
+
+	return out.String()
+}
+
+func fmtInstruction(def *Definition, operands []int) string {
+	if len(operands) != len(def.OperandWidths) {
+		return fmt.Sprintf("ERROR: operand len %d does not match defined %d\n", len(operands), len(def.OperandWidths))
+	}
+
+	var out bytes.Buffer
+	out.WriteString(def.Name)
+	for _, o := range operands {
+		fmt.Fprintf(&out, " %d", o)
+	}
+
+	return out.String()
+}
@@ -0,0 +1,1100 @@
+package compiler
+
+import (
+	"fmt"
+	"path/filepath"
+	"pythia/ast"
+	"pythia/object"
+	"pythia/token"
+	"strings"
+)
+
+// MAX_OPERAND is the largest value of 2 bytes operand, e.g. constant index and jump position
+const MAX_OPERAND = 1<<16 - 1
+
+// Bytecode is a compiled program. Main is the top-level code of program.
+type Bytecode struct {
+	Main       *CompiledFunction
+	Constants  []object.Object
+	NumGlobals int
+}
+
+// Compiler lowers ast.Program to Bytecode. Constants and global symbols are kept between compilations, for REPL.
+type Compiler struct {
+	constants []object.Object
+	names     map[string]int // constant index of names, e.g. method name
+
+	globals *SymbolTable
+	symbols *SymbolTable // innermost scope
+
+	fn *functionState // function being compiled
+}
+
+type functionState struct {
+	instructions Instructions
+	positions    map[int]token.Position
+	callSites    map[int]token.Position
+	names        map[int]string
+	contexts     []*context // blocks which need cleanup when break, continue or return leaves them
+}
+
+type contextKind int
+
+const (
+	SCOPE_CONTEXT      contextKind = iota // runtime block scope
+	LOOP_CONTEXT                          // loop which can be a target of break and continue
+	TRY_CONTEXT                           // active error handler, and finally block which runs on leaving
+	STACK_ITEM_CONTEXT                    // a value on the stack, e.g. error which is raised again after finally
+)
+
+type context struct {
+	kind contextKind
+
+	// only for loop
+	label       string
+	hasScope    bool
+	hasIterator bool
+	breaks      []int // positions of jumps to the end of loop
+	continues   []int // positions of jumps to the next iteration
+
+	// only for try
+	finally *ast.BlockStatement
+	symbols *SymbolTable // scope of try statement, finally block is compiled in it
+}
+
+func New() *Compiler {
+	globals := NewSymbolTable()
+
+	return &Compiler{
+		constants: []object.Object{},
+		names:     map[string]int{},
+		globals:   globals,
+		symbols:   globals,
+	}
+}
+
+// DefineGlobal defines global variable which is set before program runs, e.g. argv
+func (c *Compiler) DefineGlobal(name string) int {
+	return c.globals.Define(name).Index
+}
+
+// Compile compiles program as a top-level code. The value of the last statement is the result of program.
+func (c *Compiler) Compile(program *ast.Program) (*Bytecode, error) {
+	c.fn = newFunctionState()
+	c.symbols = c.globals
+
+	// functions can update globals which are defined after them
+	for _, name := range declarations(program.Statements) {
+		c.globals.Define(name)
+	}
+
+	// the last popped value is the result of program, and statements except expression are nil like evaluator
+	for _, stmt := range program.Statements {
+		if es, ok := stmt.(*ast.ExpressionStatement); ok {
+			if err := c.compileExpression(es.Expression); err != nil {
+				return nil, err
+			}
+			c.emit(OpPop)
+			continue
+		}
+
+		if err := c.compileStatement(stmt); err != nil {
+			return nil, err
+		}
+		c.emit(OpNil)
+		c.emit(OpPop)
+	}
+
+	main := c.newCompiledFunction("", 0, 0, "")
+
+	if len(c.constants) > MAX_OPERAND || c.globals.NumDefinitions() > MAX_OPERAND {
+		return nil, fmt.Errorf("too many constants or globals")
+	}
+	// jump positions are 2 bytes operands
+	if len(main.Instructions) > MAX_OPERAND {
+		return nil, fmt.Errorf("too many instructions")
+	}
+
+	return &Bytecode{Main: main, Constants: c.constants, NumGlobals: c.globals.NumDefinitions()}, nil
+}
+
+func newFunctionState() *functionState {
+	return &functionState{
+		instructions: Instructions{},
+		positions:    map[int]token.Position{},
+		callSites:    map[int]token.Position{},
+		names:        map[int]string{},
+	}
+}
+
+func (c *Compiler) newCompiledFunction(name string, numLocals int, numParameters int, source string) *CompiledFunction {
+	return &CompiledFunction{
+		Instructions:  c.fn.instructions,
+		NumLocals:     numLocals,
+		NumParameters: numParameters,
+		Name:          name,
+		Positions:     c.fn.positions,
+		CallSites:     c.fn.callSites,
+		Names:         c.fn.names,
+		source:        source,
+	}
+}
+
+func (c *Compiler) compileStatements(stmts []ast.Statement) error {
+	for _, stmt := range stmts {
+		if err := c.compileStatement(stmt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Compiler) compileStatement(stmt ast.Statement) error {
+	switch stmt := stmt.(type) {
+	case *ast.ExpressionStatement:
+		// result of assignment is not used in block
+		if ae, ok := stmt.Expression.(*ast.AssignmentExpression); ok {
+			return c.compileAssignment(ae, false)
+		}
+
+		if err := c.compileExpression(stmt.Expression); err != nil {
+			return err
+		}
+		c.emit(OpPop)
+	case *ast.LetStatement:
+		if err := c.compileExpression(stmt.Value); err != nil {
+			return err
+		}
+		c.bind(stmt.Name.Value)
+	case *ast.FunctionStatement:
+		if err := c.compileFunction(stmt.Name.Value, stmt.Parameters, stmt.Body, stmt.Name, stmt.IsGenerator); err != nil {
+			return err
+		}
+		c.bind(stmt.Name.Value)
+	case *ast.ReturnStatement:
+		return c.compileReturnStatement(stmt)
+	case *ast.YieldStatement:
+		if stmt.Value == nil {
+			c.emit(OpNull)
+		} else if err := c.compileExpression(stmt.Value); err != nil {
+			return err
+		}
+		c.emitAt(stmt, OpYield)
+	case *ast.IfStatement:
+		return c.compileIfStatement(stmt)
+	case *ast.ForStatement:
+		return c.compileForStatement(stmt)
+	case *ast.LoopStatement:
+		return c.compileLoopStatement(stmt)
+	case *ast.BranchStatement:
+		return c.compileBranchStatement(stmt)
+	case *ast.TryStatement:
+		return c.compileTryStatement(stmt)
+	case *ast.ThrowStatement:
+		if err := c.compileExpression(stmt.Value); err != nil {
+			return err
+		}
+		c.emitAt(stmt, OpThrow)
+	case *ast.ImportStatement:
+		return c.compileImportStatement(stmt)
+	case *ast.InstructionStatement:
+		c.emitAt(stmt, OpInstruction, c.addName(stmt.Instruction))
+	default:
+		return fmt.Errorf("compile error: unknown statement %T", stmt)
+	}
+
+	return nil
+}
+
+func (c *Compiler) compileExpression(exp ast.Expression) error {
+	switch exp := exp.(type) {
+	case *ast.IntegerLiteral:
+		if exp.Big != nil {
+			c.emit(OpConstant, c.addConstant(&object.BigInt{Value: exp.Big}))
+		} else {
+			c.emit(OpConstant, c.addConstant(&object.Integer{Value: exp.Value}))
+		}
+	case *ast.FloatLiteral:
+		c.emit(OpConstant, c.addConstant(&object.Float{Value: exp.Value}))
+	case *ast.StringLiteral:
+		c.emit(OpConstant, c.addConstant(&object.String{Value: exp.Value}))
+	case *ast.Boolean:
+		if exp.Value {
+			c.emit(OpTrue)
+		} else {
+			c.emit(OpFalse)
+		}
+	case *ast.NullLiteral:
+		c.emit(OpNull)
+	case *ast.Identifier:
+		c.loadVariable(exp)
+	case *ast.ArrayLiteral:
+		for _, el := range exp.Elements {
+			if err := c.compileExpression(el); err != nil {
+				return err
+			}
+		}
+		c.emit(OpArray, len(exp.Elements))
+	case *ast.HashLiteral:
+		for _, key := range exp.Keys {
+			if err := c.compileExpression(key); err != nil {
+				return err
+			}
+			if err := c.compileExpression(exp.Pairs[key]); err != nil {
+				return err
+			}
+		}
+		c.emitAt(exp, OpHash, len(exp.Pairs)*2)
+	case *ast.PrefixExpression:
+		return c.compilePrefixExpression(exp)
+	case *ast.InfixExpression:
+		return c.compileInfixExpression(exp)
+	case *ast.ConditionalExpression:
+		return c.compileConditionalExpression(exp)
+	case *ast.AssignmentExpression:
+		return c.compileAssignment(exp, true)
+	case *ast.IndexExpression:
+		if err := c.compileExpression(exp.Left); err != nil {
+			return err
+		}
+		if err := c.compileExpression(exp.Index); err != nil {
+			return err
+		}
+		c.emitAt(exp, OpIndex)
+	case *ast.SliceExpression:
+		if err := c.compileExpression(exp.Left); err != nil {
+			return err
+		}
+		if err := c.compileSliceBounds(exp); err != nil {
+			return err
+		}
+		c.emitAt(exp, OpSlice)
+	case *ast.CallExpression:
+		return c.compileCallExpression(exp)
+	case *ast.MethodCallExpression:
+		return c.compileMethodCallExpression(exp)
+	case *ast.AttributeExpression:
+		if err := c.compileExpression(exp.Object); err != nil {
+			return err
+		}
+		c.emitAt(exp, OpAttribute, c.addName(exp.Name.Value))
+	case *ast.FunctionLiteral:
+		return c.compileFunction("", exp.Parameters, exp.Body, nil, exp.IsGenerator)
+	default:
+		return fmt.Errorf("compile error: unknown expression %T", exp)
+	}
+
+	return nil
+}
+
+var infixOperators = map[string]Opcode{
+	"+":  OpAdd,
+	"-":  OpSub,
+	"*":  OpMul,
+	"/":  OpDiv,
+	"%":  OpMod,
+	"&":  OpBitAnd,
+	"|":  OpBitOr,
+	"^":  OpBitXor,
+	"<<": OpShiftLeft,
+	">>": OpShiftRight,
+	"==": OpEqual,
+	"!=": OpNotEqual,
+	"<":  OpLess,
+	">":  OpGreater,
+	"<=": OpLessEqual,
+	">=": OpGreaterEqual,
+}
+
+// OperatorOf returns the operator of binary or prefix opcode
+func OperatorOf(op Opcode) string {
+	for operator, opcode := range infixOperators {
+		if opcode == op {
+			return operator
+		}
+	}
+
+	switch op {
+	case OpMinus:
+		return "-"
+	case OpBang:
+		return "!"
+	}
+
+	return ""
+}
+
+func (c *Compiler) compilePrefixExpression(pe *ast.PrefixExpression) error {
+	if err := c.compileExpression(pe.Right); err != nil {
+		return err
+	}
+
+	switch pe.Operator {
+	case "-":
+		c.emitAt(pe, OpMinus)
+	case "!":
+		c.emitAt(pe, OpBang)
+	default:
+		return fmt.Errorf("compile error: unknown operator %s", pe.Operator)
+	}
+
+	return nil
+}
+
+func (c *Compiler) compileInfixExpression(ie *ast.InfixExpression) error {
+	if ie.Operator == "&&" || ie.Operator == "||" {
+		return c.compileLogicalExpression(ie)
+	}
+
+	op, ok := infixOperators[ie.Operator]
+	if !ok {
+		return fmt.Errorf("compile error: unknown operator %s", ie.Operator)
+	}
+
+	if err := c.compileExpression(ie.Left); err != nil {
+		return err
+	}
+	if err := c.compileExpression(ie.Right); err != nil {
+		return err
+	}
+	c.emitAt(ie, op)
+
+	return nil
+}
+
+// compileLogicalExpression evaluates right operand only if left operand can't decide the result
+func (c *Compiler) compileLogicalExpression(ie *ast.InfixExpression) error {
+	if err := c.compileExpression(ie.Left); err != nil {
+		return err
+	}
+
+	op := OpJumpIfFalseOrPop
+	if ie.Operator == "||" {
+		op = OpJumpIfTrueOrPop
+	}
+	jump := c.emit(op, MAX_OPERAND)
+
+	if err := c.compileExpression(ie.Right); err != nil {
+		return err
+	}
+	c.patchJump(jump)
+
+	return nil
+}
+
+func (c *Compiler) compileConditionalExpression(ce *ast.ConditionalExpression) error {
+	if err := c.compileExpression(ce.Condition); err != nil {
+		return err
+	}
+	jumpToAlternative := c.emit(OpJumpIfFalse, MAX_OPERAND)
+
+	if err := c.compileExpression(ce.Consequence); err != nil {
+		return err
+	}
+	jumpToEnd := c.emit(OpJump, MAX_OPERAND)
+
+	c.patchJump(jumpToAlternative)
+	if err := c.compileExpression(ce.Alternative); err != nil {
+		return err
+	}
+	c.patchJump(jumpToEnd)
+
+	return nil
+}
+
+// compileAssignment compiles assignment, it pushes nil as the result of expression if needsResult
+func (c *Compiler) compileAssignment(ae *ast.AssignmentExpression, needsResult bool) error {
+	operator := assignOperator(ae.Operator)
+	if operator < 0 {
+		return fmt.Errorf("compile error: unknown assignment operator %s", ae.Operator)
+	}
+
+	switch left := ae.Left.(type) {
+	case *ast.Identifier:
+		if err := c.compileExpression(ae.Value); err != nil {
+			return err
+		}
+
+		symbol, depth := c.symbols.Resolve(left.Value)
+		var pos int
+		if symbol.Scope == GLOBAL_SCOPE {
+			pos = c.emitAt(ae, OpAssignGlobal, symbol.Index, operator)
+		} else {
+			pos = c.emitAt(ae, OpAssignLocal, depth, symbol.Index, operator)
+		}
+		c.fn.names[pos] = left.Value
+	case *ast.IndexExpression:
+		if err := c.compileExpression(left.Index); err != nil {
+			return err
+		}
+		if err := c.compileExpression(ae.Value); err != nil {
+			return err
+		}
+		if err := c.compileExpression(left.Left); err != nil {
+			return err
+		}
+
+		pos := c.emitAt(ae, OpSetIndex, operator)
+		c.fn.names[pos] = left.Left.String()
+	case *ast.SliceExpression:
+		if err := c.compileSliceBounds(left); err != nil {
+			return err
+		}
+		if err := c.compileExpression(ae.Value); err != nil {
+			return err
+		}
+		if err := c.compileExpression(left.Left); err != nil {
+			return err
+		}
+
+		pos := c.emitAt(ae, OpSetSlice, operator)
+		c.fn.names[pos] = left.Left.String()
+	default:
+		if err := c.compileExpression(ae.Value); err != nil {
+			return err
+		}
+		c.emit(OpPop)
+
+		err := &object.Error{Kind: object.TYPE_ERROR, Message: "cannot assign to " + ae.Left.String()}
+		c.emitAt(ae, OpRaise, c.addConstant(err))
+	}
+
+	if needsResult {
+		c.emit(OpNil)
+	}
+
+	return nil
+}
+
+// compileSliceBounds pushes start, end and step of slice, omitted bound is null
+func (c *Compiler) compileSliceBounds(se *ast.SliceExpression) error {
+	for _, bound := range []ast.Expression{se.Start, se.End, se.Step} {
+		if bound == nil {
+			c.emit(OpNull)
+			continue
+		}
+		if err := c.compileExpression(bound); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func assignOperator(operator string) int {
+	for i, op := range ASSIGN_OPERATORS {
+		if op == operator {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (c *Compiler) compileCallExpression(ce *ast.CallExpression) error {
+	if err := c.compileExpression(ce.Function); err != nil {
+		return err
+	}
+
+	for _, arg := range ce.Arguments {
+		if err := c.compileExpression(arg); err != nil {
+			return err
+		}
+	}
+	if len(ce.Arguments) > 255 {
+		return fmt.Errorf("compile error: too many arguments at %s", ce.Pos())
+	}
+
+	pos := c.emitAt(ce, OpCall, len(ce.Arguments))
+	c.fn.callSites[pos] = ce.Function.Pos()
+
+	return nil
+}
+
+func (c *Compiler) compileMethodCallExpression(mce *ast.MethodCallExpression) error {
+	method, ok := mce.Call.(*ast.CallExpression)
+	if !ok {
+		return fmt.Errorf("compile error: wrong type method: %s", mce.Call.String())
+	}
+
+	if err := c.compileExpression(mce.Object); err != nil {
+		return err
+	}
+
+	for _, arg := range method.Arguments {
+		if err := c.compileExpression(arg); err != nil {
+			return err
+		}
+	}
+	if len(method.Arguments) > 255 {
+		return fmt.Errorf("compile error: too many arguments at %s", mce.Pos())
+	}
+
+	pos := c.emitAt(mce, OpMethodCall, c.addName(method.Function.String()), len(method.Arguments))
+	c.fn.callSites[pos] = method.Function.Pos()
+
+	return nil
+}
+
+// compileFunction compiles function body to a constant, and emits closure of it.
+// name is nil for function literal.
+func (c *Compiler) compileFunction(name string, params []*ast.Identifier, body *ast.BlockStatement, nameIdent *ast.Identifier, isGenerator bool) error {
+	source := (&object.Function{Parameters: params, Name: nameIdent, Body: body}).Inspect()
+
+	outerFn, outerSymbols := c.fn, c.symbols
+	c.fn = newFunctionState()
+	c.symbols = NewEnclosedSymbolTable(outerSymbols)
+
+	for _, param := range params {
+		c.symbols.Define(param.Value)
+	}
+	c.declare(body.Statements)
+
+	if err := c.compileStatements(body.Statements); err != nil {
+		return err
+	}
+	c.emit(OpNil)
+	c.emit(OpReturn)
+
+	if len(c.fn.instructions) > MAX_OPERAND {
+		return fmt.Errorf("compile error: too many instructions in function at %s", body.Pos())
+	}
+
+	fn := c.newCompiledFunction(name, c.symbols.NumDefinitions(), len(params), source)
+	fn.IsGenerator = isGenerator
+
+	c.fn, c.symbols = outerFn, outerSymbols
+
+	c.emit(OpClosure, c.addConstant(fn))
+
+	return nil
+}
+
+func (c *Compiler) compileReturnStatement(rs *ast.ReturnStatement) error {
+	if rs.ReturnValue == nil {
+		c.emit(OpNil)
+	} else if err := c.compileExpression(rs.ReturnValue); err != nil {
+		return err
+	}
+
+	// finally blocks must run before return, and return value stays on the stack
+	if c.hasTryContext() {
+		if err := c.exitContexts(-1, true); err != nil {
+			return err
+		}
+	}
+
+	c.emitAt(rs, OpReturn)
+
+	return nil
+}
+
+func (c *Compiler) compileIfStatement(is *ast.IfStatement) error {
+	if err := c.compileExpression(is.Condition); err != nil {
+		return err
+	}
+	jumpToAlternative := c.emit(OpJumpIfFalse, MAX_OPERAND)
+
+	if err := c.compileScopedBlock(is.Consequence, nil); err != nil {
+		return err
+	}
+
+	if is.Alternative == nil {
+		c.patchJump(jumpToAlternative)
+		return nil
+	}
+
+	jumpToEnd := c.emit(OpJump, MAX_OPERAND)
+	c.patchJump(jumpToAlternative)
+
+	if err := c.compileScopedBlock(is.Alternative, nil); err != nil {
+		return err
+	}
+	c.patchJump(jumpToEnd)
+
+	return nil
+}
+
+func (c *Compiler) compileForStatement(fs *ast.ForStatement) error {
+	if err := c.compileExpression(fs.Container); err != nil {
+		return err
+	}
+	c.emitAt(fs, OpIterInit)
+
+	loop := &context{kind: LOOP_CONTEXT, label: labelOf(fs.Label), hasScope: true, hasIterator: true}
+
+	// loop variables are always new variables of loop scope
+	outerSymbols := c.symbols
+	c.symbols = NewEnclosedSymbolTable(outerSymbols)
+	value := c.symbols.Define(fs.Value.Value)
+	var index Symbol
+	if fs.Index != nil {
+		index = c.symbols.Define(fs.Index.Value)
+	}
+	c.declare(fs.Body.Statements)
+
+	c.emit(OpPushScope, c.symbols.NumDefinitions())
+	c.pushContext(loop)
+
+	next := len(c.fn.instructions)
+	hasIndex := 0
+	if fs.Index != nil {
+		hasIndex = 1
+	}
+	exhausted := c.emitAt(fs, OpIterNext, MAX_OPERAND, hasIndex)
+
+	if fs.Index != nil {
+		c.emit(OpSetLocal, 0, index.Index)
+	}
+	c.emit(OpSetLocal, 0, value.Index)
+
+	if err := c.compileStatements(fs.Body.Statements); err != nil {
+		return err
+	}
+	c.emit(OpJump, next)
+
+	c.popContext()
+	c.symbols = outerSymbols
+
+	c.patchJump(exhausted)
+	for _, pos := range loop.breaks {
+		c.patchJump(pos)
+	}
+	for _, pos := range loop.continues {
+		c.changeOperand(pos, next)
+	}
+
+	c.emit(OpPopScope)
+	c.emit(OpPop) // iterator
+
+	return nil
+}
+
+func (c *Compiler) compileLoopStatement(ls *ast.LoopStatement) error {
+	loop := &context{kind: LOOP_CONTEXT, label: labelOf(ls.Label)}
+
+	stmts := ls.Body.Statements
+	if ls.Init != nil {
+		stmts = append([]ast.Statement{ls.Init}, stmts...)
+	}
+
+	outerSymbols := c.symbols
+	if len(declarations(stmts)) > 0 {
+		loop.hasScope = true
+
+		c.symbols = NewEnclosedSymbolTable(outerSymbols)
+		c.declare(stmts)
+		c.emit(OpPushScope, c.symbols.NumDefinitions())
+	}
+	c.pushContext(loop)
+
+	if ls.Init != nil {
+		if err := c.compileStatement(ls.Init); err != nil {
+			return err
+		}
+	}
+
+	condition := len(c.fn.instructions)
+	if ls.Condition != nil {
+		if err := c.compileExpression(ls.Condition); err != nil {
+			return err
+		}
+		loop.breaks = append(loop.breaks, c.emit(OpJumpIfFalse, MAX_OPERAND))
+	}
+
+	if err := c.compileStatements(ls.Body.Statements); err != nil {
+		return err
+	}
+
+	for _, pos := range loop.continues {
+		c.patchJump(pos)
+	}
+	if ls.Post != nil {
+		if err := c.compileStatement(&ast.ExpressionStatement{Token: ls.Token, Expression: ls.Post}); err != nil {
+			return err
+		}
+	}
+	c.emit(OpJump, condition)
+
+	c.popContext()
+	c.symbols = outerSymbols
+
+	for _, pos := range loop.breaks {
+		c.patchJump(pos)
+	}
+
+	if loop.hasScope {
+		c.emit(OpPopScope)
+	}
+
+	return nil
+}
+
+func labelOf(label *ast.Identifier) string {
+	if label == nil {
+		return ""
+	}
+
+	return label.Value
+}
+
+func (c *Compiler) compileBranchStatement(bs *ast.BranchStatement) error {
+	label := labelOf(bs.Label)
+
+	target := -1
+	for i := len(c.fn.contexts) - 1; i >= 0; i-- {
+		ctx := c.fn.contexts[i]
+		if ctx.kind == LOOP_CONTEXT && (label == "" || ctx.label == label) {
+			target = i
+			break
+		}
+	}
+	if target < 0 {
+		return fmt.Errorf("compile error: %s is not in a loop at %s", bs.TokenLiteral(), bs.Pos())
+	}
+
+	if err := c.exitContexts(target, false); err != nil {
+		return err
+	}
+
+	loop := c.fn.contexts[target]
+	jump := c.emit(OpJump, MAX_OPERAND)
+	if bs.Token.Type == token.BREAK {
+		loop.breaks = append(loop.breaks, jump)
+	} else {
+		loop.continues = append(loop.continues, jump)
+	}
+
+	return nil
+}
+
+// compileTryStatement compiles try statement as below. finally block is copied to every exit of try and catch block.
+//
+//	    OpTry catch
+//	    BODY
+//	    OpPopTry
+//	    FINALLY
+//	    OpJump end
+//	catch:
+//	    OpTry finally
+//	    set error to catch parameter
+//	    CATCH
+//	    OpPopTry
+//	    FINALLY
+//	    OpJump end
+//	finally:
+//	    FINALLY
+//	    OpRethrow
+//	end:
+func (c *Compiler) compileTryStatement(ts *ast.TryStatement) error {
+	var jumpsToEnd []int
+
+	handler := c.emit(OpTry, MAX_OPERAND)
+	c.pushContext(&context{kind: TRY_CONTEXT, finally: ts.Finally, symbols: c.symbols})
+	if err := c.compileScopedBlock(ts.Body, nil); err != nil {
+		return err
+	}
+	c.popContext()
+	c.emit(OpPopTry)
+	if err := c.compileFinally(ts.Finally); err != nil {
+		return err
+	}
+	jumpsToEnd = append(jumpsToEnd, c.emit(OpJump, MAX_OPERAND))
+
+	c.patchJump(handler)
+
+	if ts.Catch != nil {
+		if ts.Finally != nil {
+			handler = c.emit(OpTry, MAX_OPERAND)
+			c.pushContext(&context{kind: TRY_CONTEXT, finally: ts.Finally, symbols: c.symbols})
+		}
+
+		if err := c.compileScopedBlock(ts.Catch, ts.Param); err != nil {
+			return err
+		}
+
+		if ts.Finally != nil {
+			c.popContext()
+			c.emit(OpPopTry)
+			if err := c.compileFinally(ts.Finally); err != nil {
+				return err
+			}
+		}
+		jumpsToEnd = append(jumpsToEnd, c.emit(OpJump, MAX_OPERAND))
+
+		if ts.Finally != nil {
+			c.patchJump(handler)
+		}
+	}
+
+	if ts.Finally != nil {
+		c.pushContext(&context{kind: STACK_ITEM_CONTEXT})
+		if err := c.compileFinally(ts.Finally); err != nil {
+			return err
+		}
+		c.popContext()
+		c.emitAt(ts, OpRethrow)
+	}
+
+	for _, pos := range jumpsToEnd {
+		c.patchJump(pos)
+	}
+
+	return nil
+}
+
+func (c *Compiler) compileFinally(finally *ast.BlockStatement) error {
+	if finally == nil {
+		return nil
+	}
+
+	return c.compileScopedBlock(finally, nil)
+}
+
+func (c *Compiler) compileImportStatement(is *ast.ImportStatement) error {
+	c.emitAt(is, OpImport, c.addName(is.Path.Value))
+
+	if is.Token.Type == token.IMPORT {
+		name := moduleName(is.Path.Value)
+		if is.Alias != nil {
+			name = is.Alias.Value
+		}
+
+		c.bind(name)
+
+		return nil
+	}
+
+	for _, name := range is.Names {
+		c.emitAt(is, OpImportName, c.addName(name.Value))
+		c.bind(name.Value)
+	}
+	c.emit(OpPop) // module
+
+	return nil
+}
+
+// moduleName is the file name of module without extension
+func moduleName(path string) string {
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// compileScopedBlock compiles block which has its own scope, like if-else, try and catch block.
+// param is a variable of the scope which is set by the value on the stack, e.g. caught error.
+func (c *Compiler) compileScopedBlock(block *ast.BlockStatement, param *ast.Identifier) error {
+	if len(declarations(block.Statements)) == 0 && param == nil {
+		return c.compileStatements(block.Statements)
+	}
+
+	outerSymbols := c.symbols
+	c.symbols = NewEnclosedSymbolTable(outerSymbols)
+
+	var paramSymbol Symbol
+	if param != nil {
+		paramSymbol = c.symbols.Define(param.Value)
+	}
+	c.declare(block.Statements)
+
+	c.emit(OpPushScope, c.symbols.NumDefinitions())
+	c.pushContext(&context{kind: SCOPE_CONTEXT})
+
+	if param != nil {
+		c.emit(OpSetLocal, 0, paramSymbol.Index)
+	}
+
+	if err := c.compileStatements(block.Statements); err != nil {
+		return err
+	}
+
+	c.popContext()
+	c.emit(OpPopScope)
+	c.symbols = outerSymbols
+
+	return nil
+}
+
+// exitContexts emits cleanup of contexts which are inner than target, innermost first.
+// Values on the stack are kept if keepStack, e.g. return value.
+func (c *Compiler) exitContexts(target int, keepStack bool) error {
+	contexts := c.fn.contexts
+
+	for i := len(contexts) - 1; i > target; i-- {
+		ctx := contexts[i]
+
+		switch ctx.kind {
+		case SCOPE_CONTEXT:
+			c.emit(OpPopScope)
+		case LOOP_CONTEXT:
+			if ctx.hasScope {
+				c.emit(OpPopScope)
+			}
+			if ctx.hasIterator && !keepStack {
+				c.emit(OpPop)
+			}
+		case STACK_ITEM_CONTEXT:
+			if !keepStack {
+				c.emit(OpPop)
+			}
+		case TRY_CONTEXT:
+			c.emit(OpPopTry)
+
+			if ctx.finally == nil {
+				continue
+			}
+
+			// finally block runs in the scope of try statement, and break or continue in it sees only outer contexts
+			symbols := c.symbols
+			c.symbols = ctx.symbols
+			c.fn.contexts = append([]*context{}, contexts[:i]...)
+
+			err := c.compileFinally(ctx.finally)
+
+			c.symbols = symbols
+			c.fn.contexts = contexts
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *Compiler) hasTryContext() bool {
+	for _, ctx := range c.fn.contexts {
+		if ctx.kind == TRY_CONTEXT {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *Compiler) pushContext(ctx *context) {
+	c.fn.contexts = append(c.fn.contexts, ctx)
+}
+
+func (c *Compiler) popContext() {
+	c.fn.contexts = c.fn.contexts[:len(c.fn.contexts)-1]
+}
+
+// declare defines variables of let, func and import statements in the current scope, like object.Environment.SetInner.
+// Variable which shadows outer one is only reserved, the outer one is used until the statement defines it.
+func (c *Compiler) declare(stmts []ast.Statement) {
+	for _, name := range declarations(stmts) {
+		if _, _, ok := c.symbols.ResolveDefined(name); ok {
+			c.symbols.Reserve(name)
+		} else {
+			c.symbols.Define(name)
+		}
+	}
+}
+
+func declarations(stmts []ast.Statement) []string {
+	var names []string
+	seen := map[string]bool{}
+
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	for _, stmt := range stmts {
+		switch stmt := stmt.(type) {
+		case *ast.LetStatement:
+			add(stmt.Name.Value)
+		case *ast.FunctionStatement:
+			add(stmt.Name.Value)
+		case *ast.ImportStatement:
+			if stmt.Token.Type == token.FROM {
+				for _, name := range stmt.Names {
+					add(name.Value)
+				}
+			} else if stmt.Alias != nil {
+				add(stmt.Alias.Value)
+			} else {
+				add(moduleName(stmt.Path.Value))
+			}
+		}
+	}
+
+	return names
+}
+
+// bind sets the value on the stack to the variable, it updates the variable of outer scope if it exists
+func (c *Compiler) bind(name string) {
+	symbol := c.symbols.Define(name)
+
+	var pos int
+	if symbol.Scope == GLOBAL_SCOPE {
+		pos = c.emit(OpSetGlobal, symbol.Index)
+	} else {
+		pos = c.emit(OpSetLocal, 0, symbol.Index)
+	}
+	c.fn.names[pos] = name
+}
+
+func (c *Compiler) loadVariable(ident *ast.Identifier) {
+	symbol, depth := c.symbols.Resolve(ident.Value)
+
+	var pos int
+	if symbol.Scope == GLOBAL_SCOPE {
+		pos = c.emitAt(ident, OpGetGlobal, symbol.Index)
+	} else {
+		pos = c.emitAt(ident, OpGetLocal, depth, symbol.Index)
+	}
+	c.fn.names[pos] = ident.Value
+}
+
+func (c *Compiler) addConstant(obj object.Object) int {
+	c.constants = append(c.constants, obj)
+	return len(c.constants) - 1
+}
+
+// addName adds string constant once for the same name
+func (c *Compiler) addName(name string) int {
+	if idx, ok := c.names[name]; ok {
+		return idx
+	}
+
+	idx := c.addConstant(&object.String{Value: name})
+	c.names[name] = idx
+
+	return idx
+}
+
+// emit appends instruction and returns its position
+func (c *Compiler) emit(op Opcode, operands ...int) int {
+	pos := len(c.fn.instructions)
+	c.fn.instructions = append(c.fn.instructions, Make(op, operands...)...)
+
+	return pos
+}
+
+// emitAt emits instruction which can raise error at the position of node
+func (c *Compiler) emitAt(node ast.Node, op Opcode, operands ...int) int {
+	pos := c.emit(op, operands...)
+	c.fn.positions[pos] = node.Pos()
+
+	return pos
+}
+
+// patchJump changes the jump position of instruction at pos to the end of instructions
+func (c *Compiler) patchJump(pos int) {
+	c.changeOperand(pos, len(c.fn.instructions))
+}
+
+func (c *Compiler) changeOperand(pos int, operand int) {
+	op := Opcode(c.fn.instructions[pos])
+	def := definitions[op]
+
+	operands, _ := ReadOperands(def, c.fn.instructions[pos+1:])
+	operands[0] = operand
+
+	copy(c.fn.instructions[pos:], Make(op, operands...))
+}
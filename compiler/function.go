@@ -0,0 +1,44 @@
+package compiler
+
+import (
+	"fmt"
+	"pythia/object"
+	"pythia/token"
+)
+
+const COMPILED_FUNCTION_OBJ = "COMPILED_FUNCTION"
+
+// CompiledFunction is a constant of function body, vm makes a closure of it at runtime.
+type CompiledFunction struct {
+	Instructions  Instructions
+	NumLocals     int // number of slots of function scope, including parameters
+	NumParameters int
+	Name          string // empty for anonymous function
+	IsGenerator   bool   // calling it returns generator instead of running body
+
+	Positions map[int]token.Position // instruction offset to the position of node, for runtime errors
+	CallSites map[int]token.Position // instruction offset of call to the position of called function
+	Names     map[int]string         // instruction offset to the name of variable, for runtime errors
+
+	source string // Inspect of tree-walking function
+}
+
+func (cf *CompiledFunction) Type() object.ObjectType { return COMPILED_FUNCTION_OBJ }
+func (cf *CompiledFunction) Inspect() string         { return cf.source }
+func (cf *CompiledFunction) Equals(o object.Object) bool {
+	obj, ok := o.(*CompiledFunction)
+	if !ok {
+		return false
+	}
+
+	return cf == obj
+}
+
+// Position returns the position of node which emits the instruction at offset
+func (cf *CompiledFunction) Position(offset int) token.Position {
+	return cf.Positions[offset]
+}
+
+func (cf *CompiledFunction) String() string {
+	return fmt.Sprintf("%s\n%s", cf.Name, cf.Instructions)
+}
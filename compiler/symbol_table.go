@@ -0,0 +1,119 @@
+package compiler
+
+type SymbolScope string
+
+const (
+	GLOBAL_SCOPE SymbolScope = "GLOBAL"
+	LOCAL_SCOPE  SymbolScope = "LOCAL"
+)
+
+type Symbol struct {
+	Name  string
+	Scope SymbolScope
+	Index int
+}
+
+// SymbolTable maps names to slots. The global table is the outermost one, and each local table is
+// a function scope or a block scope which has its own slots at runtime, like object.Environment.
+type SymbolTable struct {
+	Outer *SymbolTable
+
+	store          map[string]Symbol
+	reserved       map[string]Symbol // only for local table. slots of names which shadow outer variables until they are defined
+	declared       map[string]bool   // only for global table. false if the name is referenced before it is defined
+	numDefinitions int
+}
+
+func NewSymbolTable() *SymbolTable {
+	return &SymbolTable{store: map[string]Symbol{}, declared: map[string]bool{}}
+}
+
+func NewEnclosedSymbolTable(outer *SymbolTable) *SymbolTable {
+	return &SymbolTable{Outer: outer, store: map[string]Symbol{}, reserved: map[string]Symbol{}}
+}
+
+func (s *SymbolTable) IsGlobal() bool { return s.Outer == nil }
+
+func (s *SymbolTable) NumDefinitions() int { return s.numDefinitions }
+
+// Define adds name to this table, or returns the symbol if it is already in this table
+func (s *SymbolTable) Define(name string) Symbol {
+	if s.IsGlobal() {
+		s.declared[name] = true
+	}
+
+	if symbol, ok := s.store[name]; ok {
+		return symbol
+	}
+	if symbol, ok := s.reserved[name]; ok {
+		delete(s.reserved, name)
+		s.store[name] = symbol
+		return symbol
+	}
+
+	scope := LOCAL_SCOPE
+	if s.IsGlobal() {
+		scope = GLOBAL_SCOPE
+	}
+
+	symbol := Symbol{Name: name, Scope: scope, Index: s.numDefinitions}
+	s.store[name] = symbol
+	s.numDefinitions++
+
+	return symbol
+}
+
+// Reserve allocates the slot of name in this local table, but the name resolves to the outer variable until Define.
+// let statement declares a new variable, so the same name refers to outer scope before it.
+func (s *SymbolTable) Reserve(name string) {
+	if _, ok := s.store[name]; ok {
+		return
+	}
+	if _, ok := s.reserved[name]; ok {
+		return
+	}
+
+	s.reserved[name] = Symbol{Name: name, Scope: LOCAL_SCOPE, Index: s.numDefinitions}
+	s.numDefinitions++
+}
+
+// Resolve finds name from this table to the global table, depth is the number of local tables to go up.
+// Unknown name is added to the global table, because it can be defined later or be a builtin function.
+func (s *SymbolTable) Resolve(name string) (Symbol, int) {
+	depth := 0
+	table := s
+	for ; !table.IsGlobal(); table = table.Outer {
+		if symbol, ok := table.store[name]; ok {
+			return symbol, depth
+		}
+		depth++
+	}
+
+	if symbol, ok := table.store[name]; ok {
+		return symbol, depth
+	}
+
+	symbol := Symbol{Name: name, Scope: GLOBAL_SCOPE, Index: table.numDefinitions}
+	table.store[name] = symbol
+	table.numDefinitions++
+
+	return symbol, depth
+}
+
+// ResolveDefined finds name which is defined in local tables or declared in the global table.
+func (s *SymbolTable) ResolveDefined(name string) (Symbol, int, bool) {
+	depth := 0
+	table := s
+	for ; !table.IsGlobal(); table = table.Outer {
+		if symbol, ok := table.store[name]; ok {
+			return symbol, depth, true
+		}
+		depth++
+	}
+
+	if table.declared[name] {
+		return table.store[name], depth, true
+	}
+
+	return Symbol{}, 0, false
+}
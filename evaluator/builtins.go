@@ -2,8 +2,8 @@ package evaluator
 
 import (
 	"fmt"
+	"math/big"
 	"pythia/object"
-	"strings"
 )
 
 var builtins = map[string]*object.Builtin{
@@ -12,26 +12,34 @@ var builtins = map[string]*object.Builtin{
 	"print":  builtinPrint(),
 	"type":   builtinType(),
 	"range":  builtinRange(),
+	"array":  builtinArray(),
+	"bytes":  builtinBytes(),
+	"runes":  builtinRunes(),
 	"delete": builtinDelete(),
 	"string": builtinString(),
+	"error":  builtinError(),
+	"next":   builtinNext(),
+	"iter":   builtinIter(),
 }
 
 func builtinLen() *object.Builtin {
 	return &object.Builtin{
-		Fn: func(args ...object.Object) object.Object {
+		Fn: func(invoker object.Invoker, args ...object.Object) object.Object {
 			if len(args) != 1 {
-				return newError("wrong number of arguments. got=%d, want=1", len(args))
+				return newError(object.TYPE_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
 			}
 
 			switch arg := args[0].(type) {
 			case *object.Array:
 				return &object.Integer{Value: int64(len(arg.Elements))}
 			case *object.String:
-				return &object.Integer{Value: int64(len(arg.Value))}
+				return &object.Integer{Value: int64(arg.Len())}
 			case *object.Hash:
-				return &object.Integer{Value: int64(len(arg.Pairs))}
+				return &object.Integer{Value: int64(arg.Len())}
+			case *object.Range:
+				return object.NewInteger(new(big.Int).SetUint64(arg.Len()))
 			default:
-				return newError("argument to len not supported, got %s", args[0].Type())
+				return newError(object.TYPE_ERROR, "argument to len not supported, got %s", args[0].Type())
 			}
 		},
 	}
@@ -39,15 +47,22 @@ func builtinLen() *object.Builtin {
 
 func builtinAppend() *object.Builtin {
 	return &object.Builtin{
-		Fn: func(args ...object.Object) object.Object {
+		Fn: func(invoker object.Invoker, args ...object.Object) object.Object {
 			if len(args) != 2 {
-				return newError("wrong number of arguments. got=%d, want=2", len(args))
+				return newError(object.TYPE_ERROR, "wrong number of arguments. got=%d, want=2", len(args))
 			}
-			if args[0].Type() != object.ARRAY_OBJ {
-				return newError("argument to append must be ARRAY, got %s", args[0].Type())
+			container := args[0]
+			if rng, ok := container.(*object.Range); ok {
+				container = rng.ToArray()
+				if isError(container) {
+					return container
+				}
+			}
+			if typeName(container) != object.ARRAY_OBJ {
+				return newError(object.TYPE_ERROR, "argument to append must be ARRAY, got %s", typeName(container))
 			}
 
-			arr := args[0].(*object.Array)
+			arr := container.(*object.Array)
 			length := len(arr.Elements)
 
 			newElements := make([]object.Object, length+1, length+1)
@@ -61,10 +76,9 @@ func builtinAppend() *object.Builtin {
 
 func builtinPrint() *object.Builtin {
 	return &object.Builtin{
-		Fn: func(args ...object.Object) object.Object {
+		Fn: func(invoker object.Invoker, args ...object.Object) object.Object {
 			for _, arg := range args {
-				msg := strings.ReplaceAll(arg.Inspect(), `\n`, "\n") // escaped version of line breaking to real line breaking
-				fmt.Printf(msg)
+				fmt.Print(arg.Inspect())
 			}
 
 			fmt.Println()
@@ -76,9 +90,9 @@ func builtinPrint() *object.Builtin {
 
 func builtinType() *object.Builtin {
 	return &object.Builtin{
-		Fn: func(args ...object.Object) object.Object {
+		Fn: func(invoker object.Invoker, args ...object.Object) object.Object {
 			if len(args) != 1 {
-				return newError("wrong number of arguments. got=%d, want=1", len(args))
+				return newError(object.TYPE_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
 			}
 
 			if args[0] == nil {
@@ -90,71 +104,166 @@ func builtinType() *object.Builtin {
 	}
 }
 
+// builtinRange returns lazy range of integers [start, end), 3rd argument is interval
 func builtinRange() *object.Builtin {
 	return &object.Builtin{
-		Fn: func(args ...object.Object) object.Object {
+		Fn: func(invoker object.Invoker, args ...object.Object) object.Object {
 			if !(len(args) == 2 || len(args) == 3) {
-				return newError("wrong number of arguments. got=%d, want= 2 or 3", len(args))
+				return newError(object.TYPE_ERROR, "wrong number of arguments. got=%d, want= 2 or 3", len(args))
 			}
 			for _, arg := range args {
-				if arg.Type() != object.INTEGER_OBJ {
-					return newError("argument %v must be Integer, got %s", arg, arg.Type())
+				// arg is nil if it is the result of void function
+				if typeName(arg) != object.INTEGER_OBJ {
+					return newError(object.TYPE_ERROR, "argument to range must be INTEGER, got %s", typeName(arg))
+				}
+				if _, ok := arg.(*object.BigInt); ok {
+					return newError(object.VALUE_ERROR, "argument %s is too large", arg.Inspect())
 				}
 			}
 
-			left := args[0].(*object.Integer).Value
-			right := args[1].(*object.Integer).Value
-			stepVal := int64(1)
+			start := args[0].(*object.Integer).Value
+			stop := args[1].(*object.Integer).Value
+			step := int64(1)
 			if len(args) == 3 {
-				stepVal = args[2].(*object.Integer).Value
+				step = args[2].(*object.Integer).Value
 			}
 
-			if left <= right {
-				if stepVal < 0 {
-					return newError("start can't be smaller than end, when step is %d", stepVal)
-				}
+			if step == 0 {
+				return newError(object.VALUE_ERROR, "step of range can't be 0")
+			}
+			if start < stop && step < 0 {
+				return newError(object.VALUE_ERROR, "start can't be smaller than end, when step is %d", step)
+			}
+			if start > stop && step > 0 {
+				return newError(object.VALUE_ERROR, "start can't be bigger than end, when step is %d", step)
+			}
 
-				arr := make([]object.Object, 0)
-				for left < right {
-					arr = append(arr, &object.Integer{Value: left})
-					left += stepVal
-				}
+			return &object.Range{Start: start, Stop: stop, Step: step}
+		},
+	}
+}
 
-				return &object.Array{Elements: arr}
-			} else {
-				if stepVal > 0 {
-					return newError("start can't be bigger than end, when step is %d", stepVal)
-				}
+// builtinArray returns a new array of elements of iterable, e.g. range, string, generator or user-defined iterable
+func builtinArray() *object.Builtin {
+	return &object.Builtin{
+		Fn: func(invoker object.Invoker, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError(object.TYPE_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
+			}
 
-				arr := make([]object.Object, 0)
-				for left > right {
-					arr = append(arr, &object.Integer{Value: left})
-					left += stepVal
+			container := args[0]
+			if _, ok := container.(*object.Hash); ok { // it may be user-defined iterable
+				container = iteratorOf(container, invokerCall(invoker), true)
+				if isError(container) {
+					return container
 				}
+			}
+
+			arr, ok := iterableElements(container)
+			if !ok {
+				return newError(object.TYPE_ERROR, "argument to array must be iterable, got %s", typeName(args[0]))
+			}
+
+			return arr
+		},
+	}
+}
+
+// iterableElements returns a new array of elements of obj, or error raised while iterating.
+// It returns false if obj is not iterable.
+func iterableElements(obj object.Object) (object.Object, bool) {
+	var iter object.Iterator
+	switch obj := obj.(type) {
+	case *object.Array:
+		return &object.Array{Elements: append([]object.Object{}, obj.Elements...)}, true
+	case *object.Range:
+		return obj.ToArray(), true
+	case object.Iterator:
+		iter = obj
+	case object.Iterable:
+		iter = obj.Iter()
+	default:
+		return nil, false
+	}
+
+	elements := []object.Object{}
+	for val, _, ok := iter.Next(); ok; val, _, ok = iter.Next() {
+		if isError(val) {
+			return val, true
+		}
+		elements = append(elements, val)
+	}
+
+	return &object.Array{Elements: elements}, true
+}
+
+// builtinBytes returns array of UTF-8 bytes of string
+func builtinBytes() *object.Builtin {
+	return &object.Builtin{
+		Fn: func(invoker object.Invoker, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError(object.TYPE_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
+			}
+			if args[0] == nil {
+				return newError(object.TYPE_ERROR, "argument to bytes must be STRING, got %s", NULL.Type())
+			}
+			str, ok := args[0].(*object.String)
+			if !ok {
+				return newError(object.TYPE_ERROR, "argument to bytes must be STRING, got %s", args[0].Type())
+			}
+
+			elements := make([]object.Object, 0, len(str.Value))
+			for i := 0; i < len(str.Value); i++ {
+				elements = append(elements, &object.Integer{Value: int64(str.Value[i])})
+			}
+
+			return &object.Array{Elements: elements}
+		},
+	}
+}
+
+// builtinRunes returns array of Unicode code points of string
+func builtinRunes() *object.Builtin {
+	return &object.Builtin{
+		Fn: func(invoker object.Invoker, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError(object.TYPE_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
+			}
+			if args[0] == nil {
+				return newError(object.TYPE_ERROR, "argument to runes must be STRING, got %s", NULL.Type())
+			}
+			str, ok := args[0].(*object.String)
+			if !ok {
+				return newError(object.TYPE_ERROR, "argument to runes must be STRING, got %s", args[0].Type())
+			}
 
-				return &object.Array{Elements: arr}
+			elements := make([]object.Object, 0, len(str.Value))
+			for _, ch := range str.Value {
+				elements = append(elements, &object.Integer{Value: int64(ch)})
 			}
+
+			return &object.Array{Elements: elements}
 		},
 	}
 }
 
 func builtinDelete() *object.Builtin {
 	return &object.Builtin{
-		Fn: func(args ...object.Object) object.Object {
+		Fn: func(invoker object.Invoker, args ...object.Object) object.Object {
 			if len(args) != 2 {
-				return newError("wrong number of arguments. got=%d, want=2", len(args))
+				return newError(object.TYPE_ERROR, "wrong number of arguments. got=%d, want=2", len(args))
 			}
 			if args[0].Type() != object.HASH_OBJ {
-				return newError("first argument of delete must be HASH, got %s", args[0].Type())
+				return newError(object.TYPE_ERROR, "first argument of delete must be HASH, got %s", args[0].Type())
 			}
 
 			hash := args[0].(*object.Hash)
 			index, ok := args[1].(object.Hashable)
 			if !ok {
-				return newError("unusable as hash key: %s", args[1].Type())
+				return newError(object.TYPE_ERROR, "unusable as hash key: %s", args[1].Type())
 			}
 
-			delete(hash.Pairs, index.HashKey())
+			hash.Delete(index.HashKey())
 
 			return nil
 		},
@@ -163,12 +272,91 @@ func builtinDelete() *object.Builtin {
 
 func builtinString() *object.Builtin {
 	return &object.Builtin{
-		Fn: func(args ...object.Object) object.Object {
+		Fn: func(invoker object.Invoker, args ...object.Object) object.Object {
 			if len(args) != 1 {
-				return newError("wrong number of arguments. got=%d, want=1", len(args))
+				return newError(object.TYPE_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
 			}
 
 			return &object.String{Value: args[0].Inspect()}
 		},
 	}
 }
+
+// builtinError creates an exception to throw. error(message) or error(kind, message)
+func builtinError() *object.Builtin {
+	return &object.Builtin{
+		Fn: func(invoker object.Invoker, args ...object.Object) object.Object {
+			if !(len(args) == 1 || len(args) == 2) {
+				return newError(object.TYPE_ERROR, "wrong number of arguments. got=%d, want=1 or 2", len(args))
+			}
+			for _, arg := range args {
+				if arg == nil {
+					return newError(object.TYPE_ERROR, "argument to error must be STRING, got %s", NULL.Type())
+				}
+				if arg.Type() != object.STRING_OBJ {
+					return newError(object.TYPE_ERROR, "argument to error must be STRING, got %s", arg.Type())
+				}
+			}
+
+			kind := object.ERROR
+			message := args[len(args)-1].(*object.String).Value
+			if len(args) == 2 {
+				kind = object.ErrorKind(args[0].(*object.String).Value)
+			}
+
+			return &object.Exception{Err: &object.Error{Kind: kind, Message: message}}
+		},
+	}
+}
+
+// builtinNext returns the next value of iterator, e.g. generator. At the end, it returns default value or raises StopIteration.
+func builtinNext() *object.Builtin {
+	return &object.Builtin{
+		Fn: func(invoker object.Invoker, args ...object.Object) object.Object {
+			if !(len(args) == 1 || len(args) == 2) {
+				return newError(object.TYPE_ERROR, "wrong number of arguments. got=%d, want=1 or 2", len(args))
+			}
+
+			if args[0] == nil {
+				return newError(object.TYPE_ERROR, "argument to next must be iterator, got %s", NULL.Type())
+			}
+			iter, ok := args[0].(object.Iterator)
+			if !ok {
+				return newError(object.TYPE_ERROR, "argument to next must be iterator, got %s", args[0].Type())
+			}
+
+			val, _, ok := iter.Next()
+			if !ok {
+				if len(args) == 2 {
+					return args[1]
+				}
+				return newError(object.STOP_ITERATION, "iterator is exhausted")
+			}
+
+			return val
+		},
+	}
+}
+
+// builtinIter returns a new iterator of iterable, so that values are taken by next. It calls `__iter__` of user-defined iterable.
+func builtinIter() *object.Builtin {
+	return &object.Builtin{
+		Fn: func(invoker object.Invoker, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError(object.TYPE_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
+			}
+			if args[0] == nil {
+				return newError(object.TYPE_ERROR, "argument to iter must be iterable, got %s", NULL.Type())
+			}
+
+			return iteratorOf(args[0], invokerCall(invoker), true)
+		},
+	}
+}
+
+// invokerCall adapts invoker to call of iteratorOf
+func invokerCall(invoker object.Invoker) func(fn object.Object, args []object.Object) object.Object {
+	return func(fn object.Object, args []object.Object) object.Object {
+		return invoker.Invoke(fn, args...)
+	}
+}
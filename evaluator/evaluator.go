@@ -6,13 +6,35 @@ import (
 	"pythia/object"
 )
 
+// MAX_CALL_DEPTH is the limit of recursion. The top level counts as a call like the frames of vm.
+const MAX_CALL_DEPTH = 1024
+
 var (
-	NULL  = &object.Null{}
-	TRUE  = &object.Boolean{Value: true}
-	FALSE = &object.Boolean{Value: false}
+	NULL  = object.NULL
+	TRUE  = object.TRUE
+	FALSE = object.FALSE
 )
 
+var callDepth = 1 // the number of running calls including the top level
+
 func Eval(node ast.Node, env *object.Environment) object.Object {
+	result := eval(node, env)
+
+	// the innermost node which produces error is the position of error
+	if err, ok := result.(*object.Error); ok {
+		if !err.Pos.IsValid() {
+			err.Pos = node.Pos()
+		}
+		// generator doesn't know where it is resumed, so the node which resumes it is the call site
+		if n := len(err.Stack); n > 0 && !err.Stack[n-1].Pos.IsValid() {
+			err.Stack[n-1].Pos = node.Pos()
+		}
+	}
+
+	return result
+}
+
+func eval(node ast.Node, env *object.Environment) object.Object {
 	switch node := node.(type) {
 
 	case *ast.Program:
@@ -27,12 +49,24 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return evalBlockStatement(node, env)
 	case *ast.ReturnStatement:
 		return evalReturnStatement(node, env)
+	case *ast.YieldStatement:
+		return evalYieldStatement(node, env)
 	case *ast.InstructionStatement:
 		return evalInstructionStatement(node)
 	case *ast.LetStatement:
 		return evalLetStatement(node, env)
 	case *ast.ForStatement:
 		return evalForStatement(node, env)
+	case *ast.LoopStatement:
+		return evalLoopStatement(node, env)
+	case *ast.BranchStatement:
+		return evalBranchStatement(node)
+	case *ast.TryStatement:
+		return evalTryStatement(node, env)
+	case *ast.ThrowStatement:
+		return evalThrowStatement(node, env)
+	case *ast.ImportStatement:
+		return evalImportStatement(node, env)
 	case *ast.AssignmentExpression:
 		return evalAssignmentExpression(node, env)
 	case *ast.CallExpression:
@@ -48,8 +82,14 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 			return index
 		}
 		return evalIndexExpression(left, index)
+	case *ast.SliceExpression:
+		return evalSliceExpression(node, env)
 	case *ast.MethodCallExpression:
 		return evalMethodCallExpression(node, env)
+	case *ast.AttributeExpression:
+		return evalAttributeExpression(node, env)
+	case *ast.ConditionalExpression:
+		return evalConditionalExpression(node, env)
 	case *ast.PrefixExpression:
 		right := Eval(node.Right, env)
 		if isError(right) {
@@ -57,6 +97,10 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		}
 		return evalPrefixExpression(node.Operator, right)
 	case *ast.InfixExpression:
+		if node.Operator == "&&" || node.Operator == "||" {
+			return evalLogicalExpression(node, env)
+		}
+
 		left := Eval(node.Left, env)
 		if isError(left) {
 			return left
@@ -69,6 +113,9 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 
 		return evalInfixExpression(node.Operator, left, right)
 	case *ast.IntegerLiteral:
+		if node.Big != nil {
+			return &object.BigInt{Value: node.Big}
+		}
 		return &object.Integer{Value: node.Value}
 	case *ast.FloatLiteral:
 		return &object.Float{Value: node.Value}
@@ -84,6 +131,8 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return evalHashLiteral(node, env)
 	case *ast.NullLiteral:
 		return NULL
+	case *ast.FunctionLiteral:
+		return &object.Function{Parameters: node.Parameters, Body: node.Body, Env: env, IsGenerator: node.IsGenerator}
 	}
 
 	return nil
@@ -96,6 +145,6 @@ func isError(obj object.Object) bool {
 	return false
 }
 
-func newError(format string, a ...interface{}) *object.Error {
-	return &object.Error{Message: fmt.Sprintf(format, a...)}
+func newError(kind object.ErrorKind, format string, a ...interface{}) *object.Error {
+	return &object.Error{Kind: kind, Message: fmt.Sprintf(format, a...)}
 }
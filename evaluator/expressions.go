@@ -2,8 +2,10 @@ package evaluator
 
 import (
 	"math"
+	"math/big"
 	"pythia/ast"
 	"pythia/object"
+	"pythia/token"
 )
 
 func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Object {
@@ -21,6 +23,10 @@ func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Ob
 }
 
 func evalAssignmentExpression(ae *ast.AssignmentExpression, env *object.Environment) object.Object {
+	if _, ok := ae.Left.(*ast.SliceExpression); ok {
+		return evalAssignmentWithSliceExpression(ae, env)
+	}
+
 	_, ok := ae.Left.(*ast.IndexExpression)
 	if ok {
 		result, ok := evalAssignmentWithIndexExpression(ae, env)
@@ -37,9 +43,13 @@ func evalAssignmentExpression(ae *ast.AssignmentExpression, env *object.Environm
 	}
 
 	ident, ok := ae.Left.(*ast.Identifier)
+	if !ok {
+		return newError(object.TYPE_ERROR, "cannot assign to %s", ae.Left.String())
+	}
+
 	currObj, ok := env.Get(ident.Value)
 	if !ok {
-		return newError("%s is not defined identifier", ident.Value)
+		return newError(object.NAME_ERROR, "%s is not defined identifier", ident.Value)
 	}
 
 	res, ok := evalAssignmentOperationHelper(ae.Operator, currObj, newObj)
@@ -54,7 +64,6 @@ func evalAssignmentExpression(ae *ast.AssignmentExpression, env *object.Environm
 
 func evalAssignmentWithIndexExpression(ae *ast.AssignmentExpression, env *object.Environment) (object.Object, bool) {
 	ie := ae.Left.(*ast.IndexExpression)
-	ident := ie.Left.(*ast.Identifier)
 	index := Eval(ie.Index, env)
 	if isError(index) {
 		return index, false
@@ -65,52 +74,60 @@ func evalAssignmentWithIndexExpression(ae *ast.AssignmentExpression, env *object
 		return newObj, false
 	}
 
-	currObj, ok := env.Get(ident.Value)
-	if !ok {
-		return newError("%s is not defined identifier", ident.Value), false
+	// container can be any expression, e.g. a[0][1] = 5
+	currObj := Eval(ie.Left, env)
+	if isError(currObj) {
+		return currObj, false
 	}
 
+	return assignIndex(ae.Operator, ie.Left.String(), currObj, index, newObj)
+}
+
+// assignIndex assigns value to index of container, name is the name of container
+func assignIndex(op string, name string, currObj, index, newObj object.Object) (object.Object, bool) {
 	switch {
-	case currObj.Type() == object.ARRAY_OBJ:
+	case typeName(currObj) == object.ARRAY_OBJ:
 		arr := currObj.(*object.Array)
-		idx := index.(*object.Integer).Value
-		max := int64(len(arr.Elements) - 1)
-		if idx < 0 || idx > max {
-			return newError("array index out of bound: %d", idx), false
+		if typeName(index) != object.INTEGER_OBJ {
+			return newError(object.TYPE_ERROR, "array index must be INTEGER, got %s", typeName(index)), false
+		}
+		idx, ok := sequenceIndex(index, int64(len(arr.Elements)))
+		if !ok {
+			return newError(object.INDEX_ERROR, "array index out of bound: %s", index.Inspect()), false
 		}
 
-		res, ok := evalAssignmentOperationHelper(ae.Operator, arr.Elements[idx], newObj)
+		res, ok := evalAssignmentOperationHelper(op, arr.Elements[idx], newObj)
 		if !ok {
 			return res, false
 		}
 
 		arr.Elements[idx] = res
-	case currObj.Type() == object.HASH_OBJ:
+	case typeName(currObj) == object.HASH_OBJ:
 		hash := currObj.(*object.Hash)
 
 		idx, ok := index.(object.Hashable)
 		if !ok {
-			return newError("unusable as hash key: %s", index.Type()), false
+			return newError(object.TYPE_ERROR, "unusable as hash key: %s", typeName(index)), false
 		}
 
-		pair, ok := hash.Pairs[idx.HashKey()]
+		pair, ok := hash.Get(idx.HashKey())
 		if !ok {
 			// It means key doesn't exist in hash. so add new key,value to hash if assign operator
-			if ae.Operator == "=" {
-				hash.Pairs[idx.HashKey()] = object.HashPair{Key: index, Value: newObj}
+			if op == "=" {
+				hash.Set(idx.HashKey(), object.HashPair{Key: index, Value: newObj})
 				return nil, true
 			}
-			return newError("%+v is not exist in hash", index), false
+			return newError(object.KEY_ERROR, "%+v is not exist in hash", index), false
 		}
 
-		res, ok := evalAssignmentOperationHelper(ae.Operator, pair.Value, newObj)
+		res, ok := evalAssignmentOperationHelper(op, pair.Value, newObj)
 		if !ok {
 			return res, false
 		}
 
-		hash.Pairs[idx.HashKey()] = object.HashPair{Key: index, Value: res}
+		hash.Set(idx.HashKey(), object.HashPair{Key: index, Value: res})
 	default:
-		return newError("%s is unknown index type, %T", ident.Value, ident), false
+		return newError(object.TYPE_ERROR, "%s is unknown index type, %s", name, typeName(currObj)), false
 	}
 
 	return nil, true
@@ -123,38 +140,48 @@ func evalAssignmentOperationHelper(op string, curr, rightOperand object.Object)
 	case "+=":
 		res := evalInfixExpression("+", curr, rightOperand)
 		if isError(res) {
-			return newError("+= operation is not supported for %s, %s", curr.Type(), rightOperand.Type()), false
+			return assignmentOperationError(res, "+=", curr, rightOperand), false
 		}
 		return res, true
 	case "-=":
 		res := evalInfixExpression("-", curr, rightOperand)
 		if isError(res) {
-			return newError("-= operation is not supported for %s, %s", curr.Type(), rightOperand.Type()), false
+			return assignmentOperationError(res, "-=", curr, rightOperand), false
 		}
 		return res, true
 	case "*=":
 		res := evalInfixExpression("*", curr, rightOperand)
 		if isError(res) {
-			return newError("* operation is not supported for %s, %s", curr.Type(), rightOperand.Type()), false
+			return assignmentOperationError(res, "*", curr, rightOperand), false
 		}
 		return res, true
 	case "/=":
 		res := evalInfixExpression("/", curr, rightOperand)
 		if isError(res) {
-			return newError("/ operation is not supported for %s, %s", curr.Type(), rightOperand.Type()), false
+			return assignmentOperationError(res, "/", curr, rightOperand), false
 		}
 		return res, true
 	case "%=":
 		res := evalInfixExpression("%", curr, rightOperand)
 		if isError(res) {
-			return newError("% operation is not supported for %s, %s", curr.Type(), rightOperand.Type()), false
+			return assignmentOperationError(res, "%", curr, rightOperand), false
 		}
 		return res, true
 	default:
-		return newError("%s is unknown assignment operator", op), false
+		return newError(object.ERROR, "%s is unknown assignment operator", op), false
 	}
 }
 
+// assignmentOperationError reports unsupported operand types as error of assignment operator,
+// errors of supported operation like division by zero are returned as they are
+func assignmentOperationError(err object.Object, op string, curr, rightOperand object.Object) object.Object {
+	if err.(*object.Error).Kind != object.TYPE_ERROR {
+		return err
+	}
+
+	return newError(object.TYPE_ERROR, "%s operation is not supported for %s, %s", op, curr.Type(), rightOperand.Type())
+}
+
 func evalCallExpression(ce *ast.CallExpression, env *object.Environment) object.Object {
 	funcName := Eval(ce.Function, env)
 	if isError(funcName) {
@@ -165,20 +192,75 @@ func evalCallExpression(ce *ast.CallExpression, env *object.Environment) object.
 		return args[0]
 	}
 
-	return applyFunction(funcName, args)
+	return callFunction(funcName, args, ce.Function.Pos())
+}
+
+// callFunction applies function, and error raised in user function records the function and pos where it is called
+func callFunction(fn object.Object, args []object.Object, pos token.Position) object.Object {
+	switch fn := fn.(type) {
+	case *object.Builtin:
+		return fn.Fn(invoker{pos: pos}, args...)
+	case *object.Function:
+		// the error is raised at the call site, without the function which is not called
+		if callDepth >= MAX_CALL_DEPTH {
+			return newError(object.ERROR, "maximum recursion depth exceeded")
+		}
+		callDepth++
+		defer func() { callDepth-- }()
+	}
+
+	result := applyFunction(fn, args)
+
+	if err, ok := result.(*object.Error); ok {
+		switch fn := fn.(type) {
+		case *object.Function:
+			err.Stack = append(err.Stack, object.Frame{Function: functionName(fn), Pos: pos})
+		case object.Invocable:
+			err.Stack = append(err.Stack, object.Frame{Function: fn.Name(), Pos: pos})
+		}
+	}
+
+	return result
 }
 
 func applyFunction(fn object.Object, args []object.Object) object.Object {
 	switch fn := fn.(type) {
 	case *object.Function:
+		if len(args) < len(fn.Parameters) {
+			return newError(object.TYPE_ERROR, "wrong number of arguments. got=%d, want=%d", len(args), len(fn.Parameters))
+		}
 		extendedEnv := extendFunctionEnv(fn, args)
+		if fn.IsGenerator {
+			return newGenerator(fn, extendedEnv)
+		}
 		evaluated := Eval(fn.Body, extendedEnv)
 		return unwrapReturnValue(evaluated)
 	case *object.Builtin:
-		return fn.Fn(args...)
+		return fn.Fn(invoker{}, args...)
+	case object.Invocable:
+		return fn.Invoke(args...)
+	case nil:
+		return newError(object.TYPE_ERROR, "not a function: %s", NULL.Type())
 	default:
-		return newError("not a function: %s", fn.Type())
+		return newError(object.TYPE_ERROR, "not a function: %s", fn.Type())
+	}
+}
+
+// invoker calls function passed to method or builtin, error raised in the function records it with the position of the call
+type invoker struct {
+	pos token.Position
+}
+
+func (inv invoker) Invoke(fn object.Object, args ...object.Object) object.Object {
+	return callFunction(fn, args, inv.pos)
+}
+
+func functionName(fn *object.Function) string {
+	if fn.Name == nil {
+		return object.ANONYMOUS_FRAME
 	}
+
+	return fn.Name.Value
 }
 
 func evalMethodCallExpression(mce *ast.MethodCallExpression, env *object.Environment) object.Object {
@@ -189,24 +271,54 @@ func evalMethodCallExpression(mce *ast.MethodCallExpression, env *object.Environ
 
 	method, ok := mce.Call.(*ast.CallExpression)
 	if !ok {
-		return newError("wrong type method: %s", method.Function.String())
+		return newError(object.TYPE_ERROR, "wrong type method: %s", method.Function.String())
 	}
 
 	args := evalExpressions(method.Arguments, env)
+	if len(args) == 1 && isError(args[0]) {
+		return args[0]
+	}
+
+	if mod, ok := obj.(*object.Module); ok {
+		fn, ok := mod.Get(method.Function.String())
+		if !ok {
+			return newError(object.ATTRIBUTE_ERROR, "module %s has no attribute %s", mod.Name, method.Function.String())
+		}
+		return callFunction(fn, args, method.Function.Pos())
+	}
 
 	callable, ok := obj.(object.Callable)
 	if !ok {
-		return newError("%s is not callable object", obj.Type())
+		return newError(object.TYPE_ERROR, "%s is not callable object", obj.Type())
 	}
 
-	result, ok := callable.Apply(method.Function.String(), env, args...)
+	result, ok := callable.Apply(method.Function.String(), invoker{pos: method.Function.Pos()}, args...)
 	if !ok {
-		return newError("%s is unknown method, %s", method.Function.String(), obj.Type())
+		return newError(object.ATTRIBUTE_ERROR, "%s is unknown method, %s", method.Function.String(), obj.Type())
 	}
 
 	return result
 }
 
+func evalAttributeExpression(ae *ast.AttributeExpression, env *object.Environment) object.Object {
+	obj := Eval(ae.Object, env)
+	if isError(obj) {
+		return obj
+	}
+
+	mod, ok := obj.(*object.Module)
+	if !ok {
+		return newError(object.ATTRIBUTE_ERROR, "%s has no attribute %s", obj.Type(), ae.Name.Value)
+	}
+
+	val, ok := mod.Get(ae.Name.Value)
+	if !ok {
+		return newError(object.ATTRIBUTE_ERROR, "module %s has no attribute %s", mod.Name, ae.Name.Value)
+	}
+
+	return val
+}
+
 func extendFunctionEnv(fn *object.Function, args []object.Object) *object.Environment {
 	env := object.NewEnclosedEnvironment(fn.Env)
 
@@ -232,7 +344,7 @@ func evalPrefixExpression(operator string, right object.Object) object.Object {
 	case "-":
 		return evalMinusPrefixOperatorExpression(right)
 	default:
-		return newError("unknown operator: %s%s", operator, right.Type())
+		return newError(object.TYPE_ERROR, "unknown operator: %s%s", operator, right.Type())
 	}
 }
 
@@ -243,13 +355,18 @@ func evalBangOperatorExpression(right object.Object) object.Object {
 func evalMinusPrefixOperatorExpression(right object.Object) object.Object {
 	value, ok := right.(object.Real)
 	if !ok {
-		return newError("unknown operator: -%s", right.Type())
+		return newError(object.TYPE_ERROR, "unknown operator: -%s", right.Type())
 	}
 
-	if right.Type() == object.INTEGER_OBJ {
-		value := right.(*object.Integer).Value
-		return &object.Integer{Value: -value}
-	} else {
+	switch right := right.(type) {
+	case *object.Integer:
+		if right.Value == math.MinInt64 {
+			return object.NewInteger(new(big.Int).Neg(big.NewInt(right.Value)))
+		}
+		return &object.Integer{Value: -right.Value}
+	case *object.BigInt:
+		return object.NewInteger(new(big.Int).Neg(right.Value))
+	default:
 		return &object.Float{Value: -value.ToFloat64()}
 	}
 }
@@ -260,10 +377,6 @@ func evalInfixExpression(operator string, left, right object.Object) object.Obje
 		return evalIntegerInfixExpression(operator, left, right)
 	case areBothRealNumber(left, right):
 		return evalRealNumberInfixExpression(operator, left, right)
-	case operator == "&&":
-		return evalLogicalAndExpression(left, right)
-	case operator == "||":
-		return evalLogicalOrExpression(left, right)
 	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
 		return evalStringInfixExpression(operator, left, right)
 	case operator == "==":
@@ -271,25 +384,52 @@ func evalInfixExpression(operator string, left, right object.Object) object.Obje
 	case operator == "!=":
 		return nativeBoolToBooleanObject(!left.Equals(right))
 	case left.Type() != right.Type():
-		return newError("type mismatch: %s %s %s", left.Type(), operator, right.Type())
+		return newError(object.TYPE_ERROR, "type mismatch: %s %s %s", left.Type(), operator, right.Type())
 	default:
-		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+		return newError(object.TYPE_ERROR, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
 	}
 }
 
 func evalIntegerInfixExpression(operator string, left, right object.Object) object.Object {
-	leftVal := left.(*object.Integer).Value
-	rightVal := right.(*object.Integer).Value
+	l, ok := left.(*object.Integer)
+	if !ok {
+		return evalBigIntInfixExpression(operator, left, right)
+	}
+	r, ok := right.(*object.Integer)
+	if !ok {
+		return evalBigIntInfixExpression(operator, left, right)
+	}
+
+	leftVal := l.Value
+	rightVal := r.Value
 	switch operator {
 	case "+":
-		return &object.Integer{Value: leftVal + rightVal}
+		if res, ok := addInt64(leftVal, rightVal); ok {
+			return &object.Integer{Value: res}
+		}
+		return evalBigIntInfixExpression(operator, left, right)
 	case "-":
-		return &object.Integer{Value: leftVal - rightVal}
+		if res, ok := subInt64(leftVal, rightVal); ok {
+			return &object.Integer{Value: res}
+		}
+		return evalBigIntInfixExpression(operator, left, right)
 	case "*":
-		return &object.Integer{Value: leftVal * rightVal}
+		if res, ok := mulInt64(leftVal, rightVal); ok {
+			return &object.Integer{Value: res}
+		}
+		return evalBigIntInfixExpression(operator, left, right)
 	case "/":
+		if rightVal == 0 {
+			return newError(object.ZERO_DIVISION_ERROR, "division by zero")
+		}
+		if leftVal == math.MinInt64 && rightVal == -1 {
+			return evalBigIntInfixExpression(operator, left, right)
+		}
 		return &object.Integer{Value: leftVal / rightVal}
 	case "%":
+		if rightVal == 0 {
+			return newError(object.ZERO_DIVISION_ERROR, "modulo by zero")
+		}
 		return &object.Integer{Value: leftVal % rightVal}
 	case "|":
 		return &object.Integer{Value: leftVal | rightVal}
@@ -298,9 +438,18 @@ func evalIntegerInfixExpression(operator string, left, right object.Object) obje
 	case "^":
 		return &object.Integer{Value: leftVal ^ rightVal}
 	case ">>":
+		if rightVal < 0 {
+			return newError(object.VALUE_ERROR, "negative shift count: %d", rightVal)
+		}
 		return &object.Integer{Value: leftVal >> rightVal}
 	case "<<":
-		return &object.Integer{Value: leftVal << rightVal}
+		if rightVal < 0 {
+			return newError(object.VALUE_ERROR, "negative shift count: %d", rightVal)
+		}
+		if res, ok := shiftLeftInt64(leftVal, rightVal); ok {
+			return &object.Integer{Value: res}
+		}
+		return evalBigIntInfixExpression(operator, left, right)
 	case "<":
 		return nativeBoolToBooleanObject(leftVal < rightVal)
 	case ">":
@@ -314,7 +463,7 @@ func evalIntegerInfixExpression(operator string, left, right object.Object) obje
 	case "!=":
 		return nativeBoolToBooleanObject(leftVal != rightVal)
 	default:
-		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+		return newError(object.TYPE_ERROR, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
 	}
 }
 
@@ -342,8 +491,14 @@ func evalRealNumberInfixExpression(operator string, left, right object.Object) o
 	case "*":
 		return &object.Float{Value: leftVal * rightVal}
 	case "/":
+		if rightVal == 0 {
+			return newError(object.ZERO_DIVISION_ERROR, "division by zero")
+		}
 		return &object.Float{Value: leftVal / rightVal}
 	case "%":
+		if rightVal == 0 {
+			return newError(object.ZERO_DIVISION_ERROR, "modulo by zero")
+		}
 		return &object.Float{Value: math.Mod(leftVal, rightVal)}
 	case "<":
 		return nativeBoolToBooleanObject(leftVal < rightVal)
@@ -358,7 +513,7 @@ func evalRealNumberInfixExpression(operator string, left, right object.Object) o
 	case "!=":
 		return nativeBoolToBooleanObject(leftVal != rightVal)
 	default:
-		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+		return newError(object.TYPE_ERROR, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
 	}
 }
 
@@ -371,9 +526,9 @@ func evalStringInfixExpression(operator string, left, right object.Object) objec
 	case "==":
 		leftVal := left.(*object.String).Value
 		rightVal := right.(*object.String).Value
-		return &object.Boolean{Value: leftVal == rightVal}
+		return nativeBoolToBooleanObject(leftVal == rightVal)
 	default:
-		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+		return newError(object.TYPE_ERROR, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
 	}
 }
 
@@ -381,32 +536,55 @@ func evalIndexExpression(left, index object.Object) object.Object {
 	switch {
 	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
 		return evalArrayIndexExpression(left, index)
+	case left.Type() == object.STRING_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalStringIndexExpression(left, index)
+	case left.Type() == object.RANGE_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalRangeIndexExpression(left, index)
 	case left.Type() == object.HASH_OBJ:
 		return evalHashIndexExpression(left, index)
 	default:
-		return newError("index operator not supported: %s", left.Type())
+		return newError(object.TYPE_ERROR, "index operator not supported: %s", left.Type())
 	}
 }
 
+// evalArrayIndexExpression returns element at index, negative index counts from the end
 func evalArrayIndexExpression(array, index object.Object) object.Object {
 	arrayObject := array.(*object.Array)
-	idx := index.(*object.Integer).Value
-	max := int64(len(arrayObject.Elements) - 1)
-	if idx < 0 || idx > max {
-		return newError("array index out of bound: %d", idx)
+	idx, ok := sequenceIndex(index, int64(len(arrayObject.Elements)))
+	if !ok {
+		return newError(object.INDEX_ERROR, "array index out of bound: %s", index.Inspect())
 	}
 	return arrayObject.Elements[idx]
 }
 
+// evalStringIndexExpression returns character at index, index counts Unicode characters
+func evalStringIndexExpression(str, index object.Object) object.Object {
+	runes := []rune(str.(*object.String).Value)
+	idx, ok := sequenceIndex(index, int64(len(runes)))
+	if !ok {
+		return newError(object.INDEX_ERROR, "string index out of bound: %s", index.Inspect())
+	}
+	return &object.String{Value: string(runes[idx])}
+}
+
+func evalRangeIndexExpression(rng, index object.Object) object.Object {
+	rangeObject := rng.(*object.Range)
+	idx, ok := rangeIndex(rangeObject, index)
+	if !ok {
+		return newError(object.INDEX_ERROR, "range index out of bound: %s", index.Inspect())
+	}
+	return &object.Integer{Value: rangeObject.At(idx)}
+}
+
 func evalHashIndexExpression(hash, index object.Object) object.Object {
 	hashObject := hash.(*object.Hash)
 
 	key, ok := index.(object.Hashable)
 	if !ok {
-		return newError("unusable as hash key: %s", index.Type())
+		return newError(object.TYPE_ERROR, "unusable as hash key: %s", index.Type())
 	}
 
-	pair, ok := hashObject.Pairs[key.HashKey()]
+	pair, ok := hashObject.Get(key.HashKey())
 	if !ok {
 		return NULL
 	}
@@ -414,26 +592,33 @@ func evalHashIndexExpression(hash, index object.Object) object.Object {
 	return pair.Value
 }
 
-func evalLogicalAndExpression(left, right object.Object) object.Object {
-	leftVal := isTruthy(left)
-
-	if !leftVal {
-		return nativeBoolToBooleanObject(false)
+// evalLogicalExpression evaluates right operand only if left operand can't decide the result,
+// and returns the deciding operand itself like python and javascript.
+func evalLogicalExpression(ie *ast.InfixExpression, env *object.Environment) object.Object {
+	left := Eval(ie.Left, env)
+	if isError(left) {
+		return left
 	}
 
-	rightVal := isTruthy(right)
+	if ie.Operator == "&&" && !isTruthy(left) {
+		return left
+	}
+	if ie.Operator == "||" && isTruthy(left) {
+		return left
+	}
 
-	return nativeBoolToBooleanObject(leftVal && rightVal)
+	return Eval(ie.Right, env)
 }
 
-func evalLogicalOrExpression(left, right object.Object) object.Object {
-	leftVal := isTruthy(left)
-
-	if leftVal {
-		return nativeBoolToBooleanObject(true)
+func evalConditionalExpression(ce *ast.ConditionalExpression, env *object.Environment) object.Object {
+	condition := Eval(ce.Condition, env)
+	if isError(condition) {
+		return condition
 	}
 
-	rightVal := isTruthy(right)
+	if isTruthy(condition) {
+		return Eval(ce.Consequence, env)
+	}
 
-	return nativeBoolToBooleanObject(leftVal || rightVal)
+	return Eval(ce.Alternative, env)
 }
@@ -0,0 +1,177 @@
+package evaluator
+
+import (
+	"pythia/ast"
+	"pythia/object"
+	"runtime"
+)
+
+const (
+	ITER_METHOD = "__iter__" // function of hash which makes it a user-defined iterable
+	NEXT_METHOD = "next"     // function of hash which makes it a user-defined iterator
+)
+
+// generatorStep is what generator body passes to the consumer
+type generatorStep struct {
+	value      object.Object
+	done       bool        // body is finished, value is its error if it raises
+	panicValue interface{} // body panics, it is passed to the consumer
+}
+
+// newGenerator runs body of generator function in a goroutine, which runs only while the consumer waits for the next value.
+// When the generator is garbage collected before it is finished, the goroutine exits without running the rest of body.
+func newGenerator(fn *object.Function, env *object.Environment) *object.Generator {
+	resume := make(chan bool)
+	steps := make(chan generatorStep)
+
+	closed := false // only the goroutine reads and writes it
+	env.SetYield(func(val object.Object) bool {
+		steps <- generatorStep{value: val}
+		if !<-resume {
+			closed = true
+		}
+		return !closed
+	})
+
+	go func() {
+		step := generatorStep{done: true}
+		defer func() {
+			if r := recover(); r != nil {
+				step = generatorStep{done: true, panicValue: r}
+			}
+			if !closed {
+				steps <- step
+			}
+		}()
+
+		if !<-resume {
+			closed = true
+			return
+		}
+
+		if result := unwrapReturnValue(Eval(fn.Body, env)); isError(result) {
+			step.value = result
+		}
+	}()
+
+	gen := &object.Generator{Name: functionName(fn), Step: func() (object.Object, bool) {
+		resume <- true
+		step := <-steps
+		if step.panicValue != nil {
+			panic(step.panicValue)
+		}
+		if step.done {
+			return step.value, step.value != nil
+		}
+
+		return step.value, true
+	}}
+	runtime.SetFinalizer(gen, func(*object.Generator) { close(resume) })
+
+	return gen
+}
+
+func evalYieldStatement(ys *ast.YieldStatement, env *object.Environment) object.Object {
+	var val object.Object
+	if ys.Value != nil {
+		val = Eval(ys.Value, env)
+		if isError(val) {
+			return val
+		}
+	}
+	if val == nil { // yield of void function call
+		val = NULL
+	}
+
+	yield := env.Yielder()
+	if yield == nil {
+		return newError(object.ERROR, "yield outside generator")
+	}
+
+	if !yield(val) {
+		// the generator is abandoned, stop the goroutine without running the rest of body
+		runtime.Goexit()
+	}
+
+	return nil
+}
+
+// getIterator returns a new iterator of container, call is used for functions of user-defined iterable.
+// A hash which has `__iter__` function is a user-defined iterable. `__iter__` returns an iterator, an iterable,
+// or a hash which has `next` function. `next` returns the next value, and raises StopIteration at the end.
+func getIterator(container object.Object, call func(fn object.Object, args []object.Object) object.Object) object.Object {
+	return iteratorOf(container, call, false)
+}
+
+// iteratorOf returns a new iterator of container. If call records functions in the stack of error like invoker,
+// the stack doesn't need `__iter__` frame.
+func iteratorOf(container object.Object, call func(fn object.Object, args []object.Object) object.Object, recorded bool) object.Object {
+	switch obj := container.(type) {
+	case object.Iterator:
+		return obj
+	case *object.Hash:
+		iterFn, ok := hashMethod(obj, ITER_METHOD)
+		if !ok {
+			break
+		}
+
+		res := call(iterFn, nil)
+		if err, ok := res.(*object.Error); ok {
+			if !recorded {
+				err.Stack = append(err.Stack, object.Frame{Function: ITER_METHOD})
+			}
+			return err
+		}
+
+		if hash, ok := res.(*object.Hash); ok {
+			if nextFn, ok := hashMethod(hash, NEXT_METHOD); ok {
+				return newIteratorOf(nextFn, call)
+			}
+		}
+		if iter, ok := res.(object.Iterator); ok {
+			return iter
+		}
+		if iterable, ok := res.(object.Iterable); ok {
+			return iterable.Iter()
+		}
+
+		return newError(object.TYPE_ERROR, "%s returned non-iterator of type %s", ITER_METHOD, res.Type())
+	}
+
+	iterable, ok := container.(object.Iterable)
+	if !ok {
+		return newError(object.TYPE_ERROR, "%s object doesn't implement the Iterable interface", container.Type())
+	}
+
+	return iterable.Iter()
+}
+
+// newIteratorOf returns iterator which calls `next` function until it raises StopIteration
+func newIteratorOf(nextFn object.Object, call func(fn object.Object, args []object.Object) object.Object) *object.Generator {
+	return &object.Generator{Name: NEXT_METHOD, Step: func() (object.Object, bool) {
+		val := call(nextFn, nil)
+		if err, ok := val.(*object.Error); ok && err.Kind == object.STOP_ITERATION {
+			return nil, false
+		}
+		if val == nil {
+			val = NULL
+		}
+
+		return val, true
+	}}
+}
+
+// hashMethod returns function of hash whose key is name
+func hashMethod(hash *object.Hash, name string) (object.Object, bool) {
+	pair, ok := hash.Get((&object.String{Value: name}).HashKey())
+	if !ok {
+		return nil, false
+	}
+
+	switch pair.Value.Type() {
+	case object.FUNCTION_OBJ, object.BUILTIN_OBJ:
+		return pair.Value, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,110 @@
+package evaluator
+
+import (
+	"math"
+	"math/big"
+	"pythia/object"
+)
+
+// MAX_SHIFT is the largest shift count of BigInt
+const MAX_SHIFT = math.MaxInt32
+
+// addInt64 returns a + b, and false if it overflows
+func addInt64(a, b int64) (int64, bool) {
+	res := a + b
+	return res, (a^res)&(b^res) >= 0
+}
+
+// subInt64 returns a - b, and false if it overflows
+func subInt64(a, b int64) (int64, bool) {
+	res := a - b
+	return res, (a^b)&(a^res) >= 0
+}
+
+// mulInt64 returns a * b, and false if it overflows
+func mulInt64(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+
+	res := a * b
+	if res/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return res, false
+	}
+
+	return res, true
+}
+
+// shiftLeftInt64 returns a << n, and false if it overflows. n must not be negative.
+func shiftLeftInt64(a, n int64) (int64, bool) {
+	if a == 0 {
+		return 0, true
+	}
+	if n >= 63 {
+		return 0, false
+	}
+
+	res := a << n
+	return res, res>>n == a
+}
+
+// evalBigIntInfixExpression computes integers with arbitrary precision, the result is Integer again if it fits in int64
+func evalBigIntInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal, _ := object.ToBigInt(left)
+	rightVal, _ := object.ToBigInt(right)
+
+	res := new(big.Int)
+	switch operator {
+	case "+":
+		res.Add(leftVal, rightVal)
+	case "-":
+		res.Sub(leftVal, rightVal)
+	case "*":
+		res.Mul(leftVal, rightVal)
+	case "/":
+		if rightVal.Sign() == 0 {
+			return newError(object.ZERO_DIVISION_ERROR, "division by zero")
+		}
+		res.Quo(leftVal, rightVal)
+	case "%":
+		if rightVal.Sign() == 0 {
+			return newError(object.ZERO_DIVISION_ERROR, "modulo by zero")
+		}
+		res.Rem(leftVal, rightVal)
+	case "&":
+		res.And(leftVal, rightVal)
+	case "|":
+		res.Or(leftVal, rightVal)
+	case "^":
+		res.Xor(leftVal, rightVal)
+	case "<<", ">>":
+		if rightVal.Sign() < 0 {
+			return newError(object.VALUE_ERROR, "negative shift count: %s", rightVal)
+		}
+		if !rightVal.IsInt64() || rightVal.Int64() > MAX_SHIFT {
+			return newError(object.VALUE_ERROR, "shift count too large: %s", rightVal)
+		}
+
+		if operator == "<<" {
+			res.Lsh(leftVal, uint(rightVal.Int64()))
+		} else {
+			res.Rsh(leftVal, uint(rightVal.Int64()))
+		}
+	case "<":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) < 0)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) > 0)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) <= 0)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) >= 0)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) == 0)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) != 0)
+	default:
+		return newError(object.TYPE_ERROR, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+
+	return object.NewInteger(res)
+}
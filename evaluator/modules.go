@@ -0,0 +1,143 @@
+package evaluator
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"pythia/ast"
+	"pythia/lexer"
+	"pythia/object"
+	"pythia/parser"
+	"pythia/token"
+	"strings"
+)
+
+// MODULE_EXT is appended to import path which has no extension
+const MODULE_EXT = ".pyt"
+
+var (
+	searchPaths []string
+	modules     = map[string]*object.Module{} // evaluated modules by absolute path
+	importing   []string                      // absolute paths of modules being evaluated, for detecting circular import
+)
+
+// SetSearchPaths sets directories to find modules in.
+// A module is searched in the directory of importing file first, then search paths, and then working directory.
+func SetSearchPaths(paths ...string) {
+	searchPaths = paths
+}
+
+func evalImportStatement(is *ast.ImportStatement, env *object.Environment) object.Object {
+	module := importModule(is.Path.Value, is.Pos().Filename)
+	if err, ok := module.(*object.Error); ok {
+		// error raised while evaluating module records where the module is imported
+		if err.Pos.IsValid() {
+			err.Stack = append(err.Stack, object.Frame{Function: object.MODULE_FRAME, Pos: is.Pos()})
+		}
+		return err
+	}
+	mod := module.(*object.Module)
+
+	if is.Token.Type == token.IMPORT {
+		name := mod.Name
+		if is.Alias != nil {
+			name = is.Alias.Value
+		}
+		env.SetInner(name, mod)
+
+		return nil
+	}
+
+	for _, name := range is.Names {
+		val, ok := mod.Get(name.Value)
+		if !ok {
+			return newError(object.IMPORT_ERROR, "cannot import name %s from %s", name.Value, mod.Name)
+		}
+		env.SetInner(name.Value, val)
+	}
+
+	return nil
+}
+
+// importModule evaluates module once, and returns cached module after that
+func importModule(path string, importer string) object.Object {
+	absPath, ok := findModule(path, importer)
+	if !ok {
+		return newError(object.IMPORT_ERROR, "no module found: %s", path)
+	}
+
+	if mod, ok := modules[absPath]; ok {
+		return mod
+	}
+
+	for i, p := range importing {
+		if p == absPath {
+			cycle := append(append([]string{}, importing[i:]...), absPath)
+			return newError(object.IMPORT_ERROR, "circular import: %s", strings.Join(cycle, " -> "))
+		}
+	}
+
+	source, err := ioutil.ReadFile(absPath)
+	if err != nil {
+		return newError(object.IMPORT_ERROR, "can't open module %s: %s", path, err)
+	}
+
+	l := lexer.NewFile(absPath, string(source))
+	p := parser.New(l)
+
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return newError(object.IMPORT_ERROR, "can't parse module %s: %s", path, p.Errors()[0])
+	}
+
+	importing = append(importing, absPath)
+	defer func() { importing = importing[:len(importing)-1] }()
+
+	mod := &object.Module{Name: moduleName(absPath), Path: absPath, Env: object.NewEnvironment()}
+
+	evaluated := Eval(program, mod.Env)
+	if err, ok := evaluated.(*object.Error); ok {
+		return err
+	}
+
+	modules[absPath] = mod
+
+	return mod
+}
+
+// findModule returns absolute path of module, path is relative to directory of importer, search paths or working directory
+func findModule(path string, importer string) (string, bool) {
+	if filepath.Ext(path) == "" {
+		path += MODULE_EXT
+	}
+
+	if filepath.IsAbs(path) {
+		return path, isFile(path)
+	}
+
+	var dirs []string
+	if importer != "" && isFile(importer) {
+		dirs = append(dirs, filepath.Dir(importer))
+	}
+	dirs = append(dirs, searchPaths...)
+	dirs = append(dirs, ".")
+
+	for _, dir := range dirs {
+		candidate, err := filepath.Abs(filepath.Join(dir, path))
+		if err == nil && isFile(candidate) {
+			return candidate, true
+		}
+	}
+
+	return "", false
+}
+
+func isFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+func moduleName(path string) string {
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
@@ -21,13 +21,13 @@ func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object
 		return builtin
 	}
 
-	return newError("identifier not found: " + node.Value)
+	return newError(object.NAME_ERROR, "identifier not found: "+node.Value)
 }
 
 func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
-	pairs := make(map[object.HashKey]object.HashPair)
+	hash := object.NewHash()
 
-	for keyNode, valueNode := range node.Pairs {
+	for _, keyNode := range node.Keys {
 		key := Eval(keyNode, env)
 		if isError(key) {
 			return key
@@ -35,19 +35,18 @@ func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Obje
 
 		hashKey, ok := key.(object.Hashable)
 		if !ok {
-			return newError("unusable as hash key: %s", key.Type())
+			return newError(object.TYPE_ERROR, "unusable as hash key: %s", key.Type())
 		}
 
-		value := Eval(valueNode, env)
+		value := Eval(node.Pairs[keyNode], env)
 		if isError(value) {
 			return value
 		}
 
-		hashed := hashKey.HashKey()
-		pairs[hashed] = object.HashPair{Key: key, Value: value}
+		hash.Set(hashKey.HashKey(), object.HashPair{Key: key, Value: value})
 	}
 
-	return &object.Hash{Pairs: pairs}
+	return hash
 }
 
 func evalArrayLiteral(al *ast.ArrayLiteral, env *object.Environment) object.Object {
@@ -0,0 +1,95 @@
+package evaluator
+
+import (
+	"pythia/object"
+	"pythia/token"
+)
+
+// Operations below are used by vm, so that compiled code behaves the same as tree-walking evaluator.
+
+func NewError(kind object.ErrorKind, format string, a ...interface{}) *object.Error {
+	return newError(kind, format, a...)
+}
+
+func IsTruthy(obj object.Object) bool {
+	return isTruthy(obj)
+}
+
+func NativeBoolToBooleanObject(input bool) *object.Boolean {
+	return nativeBoolToBooleanObject(input)
+}
+
+func LookupBuiltin(name string) (*object.Builtin, bool) {
+	builtin, ok := builtins[name]
+	return builtin, ok
+}
+
+func PrefixOperation(operator string, right object.Object) object.Object {
+	return evalPrefixExpression(operator, right)
+}
+
+func InfixOperation(operator string, left, right object.Object) object.Object {
+	return evalInfixExpression(operator, left, right)
+}
+
+func IndexOperation(left, index object.Object) object.Object {
+	return evalIndexExpression(left, index)
+}
+
+// SliceOperation returns slice of left, omitted bounds are null
+func SliceOperation(left, start, end, step object.Object) object.Object {
+	return evalSlice(left, start, end, step)
+}
+
+// AssignSlice replaces slice of container with elements of value, and returns error if it fails
+func AssignSlice(operator string, name string, container, start, end, step, value object.Object) object.Object {
+	return assignSlice(operator, name, container, start, end, step, value)
+}
+
+// AssignOperation returns the new value of `curr op value`, e.g. curr += value
+func AssignOperation(operator string, curr, value object.Object) object.Object {
+	res, _ := evalAssignmentOperationHelper(operator, curr, value)
+	return res
+}
+
+// AssignIndex assigns value to index of container, and returns error if it fails. name is the name of container
+func AssignIndex(operator string, name string, container, index, value object.Object) object.Object {
+	res, ok := assignIndex(operator, name, container, index, value)
+	if !ok {
+		return res
+	}
+
+	return nil
+}
+
+// ApplyFunction calls builtin or function of tree-walking evaluator without recording it in the stack of error
+func ApplyFunction(fn object.Object, args []object.Object) object.Object {
+	return applyFunction(fn, args)
+}
+
+// GetIterator returns a new iterator of container, call is used for functions of user-defined iterable
+func GetIterator(container object.Object, call func(fn object.Object, args []object.Object) object.Object) object.Object {
+	return getIterator(container, call)
+}
+
+// CallFunction calls builtin or function of tree-walking evaluator, e.g. function of imported module
+func CallFunction(fn object.Object, args []object.Object, pos token.Position) object.Object {
+	return callFunction(fn, args, pos)
+}
+
+func FunctionName(fn *object.Function) string {
+	return functionName(fn)
+}
+
+func Throw(val object.Object) *object.Error {
+	return throwValue(val)
+}
+
+func RunInstruction(instruction string) object.Object {
+	return runInstruction(instruction)
+}
+
+// ImportModule evaluates module of path once, importer is the filename of importing source
+func ImportModule(path string, importer string) object.Object {
+	return importModule(path, importer)
+}
@@ -0,0 +1,295 @@
+package evaluator
+
+import (
+	"math"
+	"math/big"
+	"pythia/ast"
+	"pythia/object"
+)
+
+func evalSliceExpression(se *ast.SliceExpression, env *object.Environment) object.Object {
+	left := Eval(se.Left, env)
+	if isError(left) {
+		return left
+	}
+
+	bounds, err := evalSliceBounds(se, env)
+	if err != nil {
+		return err
+	}
+
+	return evalSlice(left, bounds[0], bounds[1], bounds[2])
+}
+
+// evalSliceBounds evaluates start, end and step of slice, omitted bound is nil
+func evalSliceBounds(se *ast.SliceExpression, env *object.Environment) ([3]object.Object, object.Object) {
+	var bounds [3]object.Object
+
+	for i, exp := range []ast.Expression{se.Start, se.End, se.Step} {
+		if exp == nil {
+			continue
+		}
+
+		bound := Eval(exp, env)
+		if isError(bound) {
+			return bounds, bound
+		}
+		bounds[i] = bound
+	}
+
+	return bounds, nil
+}
+
+// evalSlice returns elements of left from start to end by step like python. Slice of range is an array.
+func evalSlice(left, start, end, step object.Object) object.Object {
+	switch left := left.(type) {
+	case *object.Array:
+		first, stepVal, count, err := sliceIndices(int64(len(left.Elements)), start, end, step)
+		if err != nil {
+			return err
+		}
+
+		elements := make([]object.Object, 0, count)
+		for i := int64(0); i < count; i++ {
+			elements = append(elements, left.Elements[first+i*stepVal])
+		}
+
+		return &object.Array{Elements: elements}
+	case *object.String:
+		runes := []rune(left.Value)
+		first, stepVal, count, err := sliceIndices(int64(len(runes)), start, end, step)
+		if err != nil {
+			return err
+		}
+
+		sliced := make([]rune, 0, count)
+		for i := int64(0); i < count; i++ {
+			sliced = append(sliced, runes[first+i*stepVal])
+		}
+
+		return &object.String{Value: string(sliced)}
+	case *object.Range:
+		first, stepVal, count, err := sliceIndices(rangeLen(left), start, end, step)
+		if err != nil {
+			return err
+		}
+		if count > object.MAX_RANGE_ELEMENTS {
+			return newError(object.VALUE_ERROR, "slice of %s is too long to convert to array", left.Inspect())
+		}
+
+		elements := make([]object.Object, 0, count)
+		for i := int64(0); i < count; i++ {
+			elements = append(elements, &object.Integer{Value: left.At(uint64(first + i*stepVal))})
+		}
+
+		return &object.Array{Elements: elements}
+	case nil:
+		return newError(object.TYPE_ERROR, "slice operator not supported: %s", NULL.Type())
+	default:
+		return newError(object.TYPE_ERROR, "slice operator not supported: %s", left.Type())
+	}
+}
+
+func evalAssignmentWithSliceExpression(ae *ast.AssignmentExpression, env *object.Environment) object.Object {
+	se := ae.Left.(*ast.SliceExpression)
+
+	bounds, err := evalSliceBounds(se, env)
+	if err != nil {
+		return err
+	}
+
+	newObj := Eval(ae.Value, env)
+	if isError(newObj) {
+		return newObj
+	}
+
+	container := Eval(se.Left, env)
+	if isError(container) {
+		return container
+	}
+
+	return assignSlice(ae.Operator, se.Left.String(), container, bounds[0], bounds[1], bounds[2], newObj)
+}
+
+// assignSlice replaces elements of array in slice with elements of iterable value, name is the name of container.
+// Slice whose step is 1 can be replaced with different number of elements, otherwise the numbers must be the same.
+func assignSlice(op string, name string, container, start, end, step, value object.Object) object.Object {
+	arr, ok := container.(*object.Array)
+	if !ok {
+		return newError(object.TYPE_ERROR, "%s is unknown slice type, %s", name, typeName(container))
+	}
+	if op != "=" {
+		return newError(object.TYPE_ERROR, "%s operation is not supported for slice", op)
+	}
+
+	values, ok := iterableElements(value)
+	if !ok {
+		return newError(object.TYPE_ERROR, "can only assign an iterable to slice, got %s", typeName(value))
+	}
+	if err, ok := values.(*object.Error); ok {
+		return err
+	}
+	elements := values.(*object.Array).Elements
+
+	first, stepVal, count, err := sliceIndices(int64(len(arr.Elements)), start, end, step)
+	if err != nil {
+		return err
+	}
+
+	if stepVal == 1 {
+		newElements := make([]object.Object, 0, int64(len(arr.Elements))-count+int64(len(elements)))
+		newElements = append(newElements, arr.Elements[:first]...)
+		newElements = append(newElements, elements...)
+		newElements = append(newElements, arr.Elements[first+count:]...)
+		arr.Elements = newElements
+
+		return nil
+	}
+
+	if int64(len(elements)) != count {
+		return newError(object.VALUE_ERROR, "attempt to assign sequence of size %d to extended slice of size %d", len(elements), count)
+	}
+	for i, element := range elements {
+		arr.Elements[first+int64(i)*stepVal] = element
+	}
+
+	return nil
+}
+
+// sliceIndices adjusts bounds of slice to sequence of length like python, negative bound counts from the end.
+// It returns the first index, step and number of elements in slice.
+func sliceIndices(length int64, start, end, step object.Object) (int64, int64, int64, *object.Error) {
+	stepVal, hasStep, err := sliceBound(step)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	if !hasStep {
+		stepVal = 1
+	}
+	if stepVal == 0 {
+		return 0, 0, 0, newError(object.VALUE_ERROR, "slice step cannot be zero")
+	}
+
+	// bounds are clamped into [lower, upper], both ends are included in backward slice
+	lower, upper := int64(0), length
+	if stepVal < 0 {
+		lower, upper = -1, length-1
+	}
+
+	adjust := func(bound object.Object, defaultVal int64) (int64, *object.Error) {
+		val, ok, err := sliceBound(bound)
+		if err != nil || !ok {
+			return defaultVal, err
+		}
+
+		if val < 0 {
+			val += length
+		}
+		if val < lower {
+			return lower, nil
+		}
+		if val > upper {
+			return upper, nil
+		}
+
+		return val, nil
+	}
+
+	// omitted bounds cover the whole sequence in the direction of step
+	firstDefault, lastDefault := lower, upper
+	if stepVal < 0 {
+		firstDefault, lastDefault = upper, lower
+	}
+
+	first, err := adjust(start, firstDefault)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	last, err := adjust(end, lastDefault)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	var count int64
+	switch {
+	case stepVal > 0 && first < last:
+		count = (last-first-1)/stepVal + 1
+	case stepVal < 0 && first > last:
+		count = int64((uint64(first)-uint64(last)-1)/(-uint64(stepVal)) + 1)
+	}
+
+	return first, stepVal, count, nil
+}
+
+// sliceBound returns the value of bound, and false if it is omitted or null.
+// Integers out of int64 range are clamped, because they are out of any sequence.
+func sliceBound(bound object.Object) (int64, bool, *object.Error) {
+	switch bound := bound.(type) {
+	case nil, *object.Null:
+		return 0, false, nil
+	case *object.Integer:
+		return bound.Value, true, nil
+	case *object.BigInt:
+		if bound.Value.Sign() < 0 {
+			return math.MinInt64, true, nil
+		}
+		return math.MaxInt64, true, nil
+	default:
+		return 0, false, newError(object.TYPE_ERROR, "slice indices must be integers or null, got %s", bound.Type())
+	}
+}
+
+// sequenceIndex returns the position of index in sequence of length, negative index counts from the end
+func sequenceIndex(index object.Object, length int64) (int64, bool) {
+	idx, ok := index.(*object.Integer)
+	if !ok {
+		return 0, false
+	}
+
+	i := idx.Value
+	if i < 0 {
+		i += length
+	}
+	if i < 0 || i >= length {
+		return 0, false
+	}
+
+	return i, true
+}
+
+// rangeIndex returns the element index of range, negative index counts from the end.
+// Index can be BigInt, because a range can have more than 2**63-1 elements.
+func rangeIndex(rng *object.Range, index object.Object) (uint64, bool) {
+	i, ok := object.ToBigInt(index)
+	if !ok {
+		return 0, false
+	}
+
+	length := new(big.Int).SetUint64(rng.Len())
+	if i.Sign() < 0 {
+		i = new(big.Int).Add(i, length)
+	}
+	if i.Sign() < 0 || i.Cmp(length) >= 0 {
+		return 0, false
+	}
+
+	return i.Uint64(), true
+}
+
+// rangeLen returns length of range as int64, a range longer than it is clamped
+func rangeLen(rng *object.Range) int64 {
+	length := rng.Len()
+	if length > math.MaxInt64 {
+		return math.MaxInt64
+	}
+
+	return int64(length)
+}
+
+func typeName(obj object.Object) object.ObjectType {
+	if obj == nil {
+		return NULL.Type()
+	}
+
+	return obj.Type()
+}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"pythia/ast"
 	"pythia/object"
+	"pythia/token"
 )
 
 func evalProgram(program *ast.Program, env *object.Environment) object.Object {
@@ -28,7 +29,7 @@ func evalLetStatement(ls *ast.LetStatement, env *object.Environment) object.Obje
 	if isError(val) {
 		return val
 	}
-	env.Set(ls.Name.Value, val)
+	env.SetInner(ls.Name.Value, val)
 
 	return nil
 }
@@ -47,13 +48,27 @@ func evalIfStatement(is *ast.IfStatement, env *object.Environment) object.Object
 		result = Eval(is.Alternative, extendIfElseEnv(env))
 	}
 
-	if result != nil && (result.Type() == object.RETURN_VALUE_OBJ || result.Type() == object.ERROR_OBJ) {
+	if isInterruption(result) {
 		return result
 	}
 
 	return nil
 }
 
+// isInterruption reports whether obj stops evaluation of the rest statements in block
+func isInterruption(obj object.Object) bool {
+	if obj == nil {
+		return false
+	}
+
+	switch obj.Type() {
+	case object.RETURN_VALUE_OBJ, object.ERROR_OBJ, object.BREAK_OBJ, object.CONTINUE_OBJ:
+		return true
+	default:
+		return false
+	}
+}
+
 func isTruthy(obj object.Object) bool {
 	switch obj {
 	case NULL:
@@ -76,11 +91,8 @@ func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) obje
 
 	for _, statement := range block.Statements {
 		result = Eval(statement, env)
-		if result != nil {
-			rt := result.Type()
-			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ {
-				return result
-			}
+		if isInterruption(result) {
+			return result
 		}
 	}
 
@@ -92,8 +104,8 @@ func evalFunctionStatement(fn *ast.FunctionStatement, env *object.Environment) o
 	name := fn.Name
 	body := fn.Body
 
-	funcObj := &object.Function{Parameters: params, Name: name, Body: body}
-	env.Set(name.Value, funcObj)
+	funcObj := &object.Function{Parameters: params, Name: name, Body: body, IsGenerator: fn.IsGenerator}
+	env.SetInner(name.Value, funcObj)
 	funcObj.Env = env
 
 	return nil
@@ -112,22 +124,30 @@ func evalReturnStatement(ret *ast.ReturnStatement, env *object.Environment) obje
 }
 
 func evalInstructionStatement(instruction *ast.InstructionStatement) object.Object {
-	switch instruction.Instruction {
+	return runInstruction(instruction.Instruction)
+}
+
+func runInstruction(instruction string) object.Object {
+	switch instruction {
 	case "quit":
 		os.Exit(0)
 	}
 
-	return newError("unknown instruction: %s", instruction.Instruction)
+	return newError(object.ERROR, "unknown instruction: %s", instruction)
 }
 
 func evalForStatement(forStmt *ast.ForStatement, env *object.Environment) object.Object {
-
 	container := Eval(forStmt.Container, env)
+	if isError(container) {
+		return container
+	}
 
-	iter, ok := container.(object.Iterator)
-	if !ok {
-		return newError("%s object doesn't implement the Iterator interface", container.Type())
+	// every loop has its own iterator, so nested loops over the same container don't interfere
+	iterObj := getIterator(container, applyFunction)
+	if isError(iterObj) {
+		return iterObj
 	}
+	iter := iterObj.(object.Iterator)
 
 	// Initialize index, value in for-loop
 	var variables []*ast.Identifier
@@ -137,17 +157,19 @@ func evalForStatement(forStmt *ast.ForStatement, env *object.Environment) object
 	}
 	extendedEnv := extendForLoopEnv(variables, env)
 
-	// loop iterator object
-	iter.Reset()
+	_, isHash := iter.(*object.HashIterator)
 
 	required, optional, ok := iter.Next()
 
 	for ok {
+		if isError(required) {
+			return required
+		}
 
 		extendedEnv.SetInner(forStmt.Value.Value, required)
 
 		if forStmt.Index != nil {
-			if container.Type() == object.HASH_OBJ {
+			if isHash {
 				extendedEnv.SetInner(forStmt.Index.Value, required)
 				extendedEnv.SetInner(forStmt.Value.Value, optional)
 			} else {
@@ -155,12 +177,9 @@ func evalForStatement(forStmt *ast.ForStatement, env *object.Environment) object
 			}
 		}
 
-		body := Eval(forStmt.Body, extendedEnv)
-		if body != nil && body.Type() == object.RETURN_VALUE_OBJ {
-			return nil
-		}
-		if isError(body) {
-			return body
+		result, stop := evalLoopBody(forStmt.Body, forStmt.Label, extendedEnv)
+		if stop {
+			return result
 		}
 
 		required, optional, ok = iter.Next()
@@ -169,11 +188,137 @@ func evalForStatement(forStmt *ast.ForStatement, env *object.Environment) object
 	return nil
 }
 
+func evalLoopStatement(loop *ast.LoopStatement, env *object.Environment) object.Object {
+	loopEnv := object.NewEnclosedEnvironment(env)
+
+	if loop.Init != nil {
+		init := Eval(loop.Init, loopEnv)
+		if isError(init) {
+			return init
+		}
+	}
+
+	for {
+		if loop.Condition != nil {
+			condition := Eval(loop.Condition, loopEnv)
+			if isError(condition) {
+				return condition
+			}
+			if !isTruthy(condition) {
+				return nil
+			}
+		}
+
+		result, stop := evalLoopBody(loop.Body, loop.Label, loopEnv)
+		if stop {
+			return result
+		}
+
+		if loop.Post != nil {
+			post := Eval(loop.Post, loopEnv)
+			if isError(post) {
+				return post
+			}
+		}
+	}
+}
+
+// evalLoopBody evaluates body of loop, and returns whether the loop must stop and the result of loop statement.
+// return, error, and break or continue for outer loop are passed to outside of the loop.
+func evalLoopBody(body *ast.BlockStatement, label *ast.Identifier, env *object.Environment) (object.Object, bool) {
+	result := Eval(body, env)
+
+	switch result := result.(type) {
+	case *object.Break:
+		if isTargetLoop(result.Label, label) {
+			return nil, true
+		}
+		return result, true
+	case *object.Continue:
+		if isTargetLoop(result.Label, label) {
+			return nil, false
+		}
+		return result, true
+	case *object.ReturnValue, *object.Error:
+		return result, true
+	}
+
+	return nil, false
+}
+
+func isTargetLoop(target string, label *ast.Identifier) bool {
+	return target == "" || (label != nil && label.Value == target)
+}
+
+func evalBranchStatement(branch *ast.BranchStatement) object.Object {
+	label := ""
+	if branch.Label != nil {
+		label = branch.Label.Value
+	}
+
+	if branch.Token.Type == token.BREAK {
+		return &object.Break{Label: label}
+	}
+
+	return &object.Continue{Label: label}
+}
+
+func evalTryStatement(ts *ast.TryStatement, env *object.Environment) object.Object {
+	result := Eval(ts.Body, object.NewEnclosedEnvironment(env))
+
+	if err, ok := result.(*object.Error); ok && ts.Catch != nil {
+		catchEnv := object.NewEnclosedEnvironment(env)
+		if ts.Param != nil {
+			catchEnv.SetInner(ts.Param.Value, &object.Exception{Err: err})
+		}
+
+		result = Eval(ts.Catch, catchEnv)
+	}
+
+	// finally block always runs, and its interruption takes precedence over the result of try or catch block
+	if ts.Finally != nil {
+		finally := Eval(ts.Finally, object.NewEnclosedEnvironment(env))
+		if isInterruption(finally) {
+			return finally
+		}
+	}
+
+	if isInterruption(result) {
+		return result
+	}
+
+	return nil
+}
+
+func evalThrowStatement(ts *ast.ThrowStatement, env *object.Environment) object.Object {
+	val := Eval(ts.Value, env)
+	if isError(val) {
+		return val
+	}
+
+	return throwValue(val)
+}
+
+// throwValue raises caught exception again, or raises a new error with the thrown value as message
+func throwValue(val object.Object) *object.Error {
+	switch val := val.(type) {
+	case *object.Exception:
+		// every throw raises a new error, so that the trace of exception thrown again starts where it is thrown
+		return &object.Error{Kind: val.Err.Kind, Message: val.Err.Message}
+	case *object.String:
+		return newError(object.ERROR, "%s", val.Value)
+	case nil:
+		return newError(object.ERROR, "null")
+	default:
+		return newError(object.ERROR, "%s", val.Inspect())
+	}
+}
+
 func extendForLoopEnv(variables []*ast.Identifier, env *object.Environment) *object.Environment {
 	extendedEnv := object.NewEnclosedEnvironment(env)
 
 	for _, v := range variables {
-		extendedEnv.Set(v.Value, NULL)
+		extendedEnv.SetInner(v.Value, NULL)
 	}
 
 	return extendedEnv
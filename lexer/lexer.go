@@ -4,44 +4,51 @@ import (
 	"fmt"
 	"pythia/token"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 type Lexer struct {
 	input        []rune
-	position     int  // 현재 문자의 위치
-	readPosition int  // 현재 문자의 다음
-	ch           rune // 현재 읽고 있는 문자
+	position     int            // 현재 문자의 위치
+	readPosition int            // 현재 문자의 다음
+	ch           rune           // 현재 읽고 있는 문자
+	pos          token.Position // 현재 문자의 소스 상 위치
+	errors       []string
 }
 
 func New(input string) *Lexer {
-	l := &Lexer{input: []rune(input)}
+	return NewFile("", input)
+}
+
+// NewFile creates lexer whose token positions are reported with the filename
+func NewFile(filename string, input string) *Lexer {
+	l := &Lexer{
+		input:  []rune(input),
+		pos:    token.Position{Filename: filename, Offset: 0, Line: 1, Column: 1},
+		errors: []string{},
+	}
 	l.readChar()
 	return l
 }
 
-func (l *Lexer) GetErrorInfo() string {
-	row := 0
-	col := 0
-	chars := len(l.input)
-	i := 0
+func (l *Lexer) Errors() []string {
+	return l.errors
+}
 
-	for i < l.readPosition && i < chars {
-		if l.input[i] == '\n' {
-			row++
-			col = 0
-		}
+func (l *Lexer) NextToken() token.Token {
+	l.skipWhiteSpace()
 
-		i++
-		col++
-	}
-	return fmt.Sprintf("got %c at line %d, around %d", l.ch, row, col)
+	pos := l.pos
+	tok := l.readToken()
+	tok.Pos = pos
+
+	return tok
 }
 
-func (l *Lexer) NextToken() token.Token {
+func (l *Lexer) readToken() token.Token {
 	var tok token.Token
 
-	l.skipWhiteSpace()
-
 	switch l.ch {
 	case '=':
 		tok = l.makeTwoCharToken(l.ch)
@@ -80,14 +87,17 @@ func (l *Lexer) NextToken() token.Token {
 	case '}':
 		tok = newToken(token.RBRACE, l.ch)
 	case '"':
-		tok.Type = token.STRING
-		tok.Literal = l.readString()
+		tok = l.newStringToken()
+	case '`':
+		tok = l.newRawStringToken()
 	case '[':
 		tok = newToken(token.LBRACKET, l.ch)
 	case ']':
 		tok = newToken(token.RBRACKET, l.ch)
 	case ':':
 		tok = newToken(token.COLON, l.ch)
+	case '?':
+		tok = newToken(token.QUESTION, l.ch)
 	case '.':
 		tok = newToken(token.DOT, l.ch)
 	case rune(0):
@@ -102,6 +112,7 @@ func (l *Lexer) NextToken() token.Token {
 			return l.newNumberToken()
 		} else {
 			tok = newToken(token.ILLEGAL, l.ch)
+			l.addError(l.pos, "illegal character %q", l.ch)
 		}
 	}
 
@@ -110,6 +121,10 @@ func (l *Lexer) NextToken() token.Token {
 }
 
 func (l *Lexer) readChar() {
+	if l.readPosition > 0 && l.position < len(l.input) {
+		l.advancePosition(l.input[l.position])
+	}
+
 	if l.readPosition >= len(l.input) {
 		l.ch = rune(0)
 	} else {
@@ -119,28 +134,76 @@ func (l *Lexer) readChar() {
 	l.readPosition++
 }
 
+// advancePosition moves position over the given character
+func (l *Lexer) advancePosition(ch rune) {
+	l.pos.Offset += utf8.RuneLen(ch)
+	if ch == '\n' {
+		l.pos.Line++
+		l.pos.Column = 1
+	} else {
+		l.pos.Column++
+	}
+}
+
 func (l *Lexer) skipWhiteSpace() {
-	for l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r' {
+	for {
+		switch {
+		case l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r':
+			l.readChar()
+		case l.ch == '/' && l.peekChar() == '/':
+			l.skipLineComment()
+		case l.ch == '/' && l.peekChar() == '*':
+			l.skipBlockComment()
+		default:
+			return
+		}
+	}
+}
+
+func (l *Lexer) skipLineComment() {
+	for l.ch != '\n' && l.ch != 0 {
 		l.readChar()
 	}
 }
 
+func (l *Lexer) skipBlockComment() {
+	start := l.pos
+
+	l.readChar() // '/'
+	l.readChar() // '*'
+	for !(l.ch == '*' && l.peekChar() == '/') {
+		if l.ch == 0 {
+			l.addError(start, "unterminated block comment")
+			return
+		}
+		l.readChar()
+	}
+
+	l.readChar() // '*'
+	l.readChar() // '/'
+}
+
+// addError records message with the position where the problem starts
+func (l *Lexer) addError(pos token.Position, format string, a ...interface{}) {
+	msg := fmt.Sprintf(format, a...)
+	l.errors = append(l.errors, fmt.Sprintf("%s at %s", msg, pos))
+}
+
 func newToken(tokenType token.TokenType, ch rune) token.Token {
 	return token.Token{Type: tokenType, Literal: string(ch)}
 }
 
 func (l *Lexer) readIdentifier() string {
 	position := l.position
-	for isLetter(l.ch) || isDigit(l.ch) {
+	for isLetter(l.ch) || unicode.IsDigit(l.ch) {
 		l.readChar()
 	}
 	return string(l.input[position:l.position])
 }
 
+// isLetter reports whether ch can start identifier, Unicode letters are allowed
 func isLetter(ch rune) bool {
-	return ('a' <= ch && ch <= 'z') ||
-		('A' <= ch && ch <= 'Z') ||
-		ch == '_'
+	return unicode.IsLetter(ch) || ch == '_'
 }
 
 func isDigit(ch rune) bool {
@@ -148,24 +211,118 @@ func isDigit(ch rune) bool {
 }
 
 func (l *Lexer) newNumberToken() token.Token {
-	var tok token.Token
+	start := l.position
 
-	tok.Literal = l.readNumber()
-	if strings.Contains(tok.Literal, ".") {
-		tok.Type = token.FLOAT
-	} else {
-		tok.Type = token.INT
+	tokenType, ok := l.readNumber()
+	if !ok {
+		l.skipMalformedNumber()
+		tokenType = token.ILLEGAL
 	}
 
-	return tok
+	return token.Token{Type: tokenType, Literal: string(l.input[start:l.position])}
 }
 
-func (l *Lexer) readNumber() string {
-	position := l.position
-	for isDigit(l.ch) || l.ch == '.' {
+// readNumber reads decimal, float, scientific and 0x/0o/0b prefixed literals.
+// On malformed literal, it reports error at the offending character.
+func (l *Lexer) readNumber() (token.TokenType, bool) {
+	if l.ch == '0' {
+		switch l.peekChar() {
+		case 'x', 'X':
+			return l.readPrefixedNumber("hexadecimal", isHexDigit)
+		case 'o', 'O':
+			return l.readPrefixedNumber("octal", isOctalDigit)
+		case 'b', 'B':
+			return l.readPrefixedNumber("binary", isBinaryDigit)
+		}
+	}
+
+	tokenType := token.TokenType(token.INT)
+
+	if !l.readDigits(isDigit) {
+		return tokenType, false
+	}
+
+	if l.ch == '.' && isDigit(l.peekChar()) {
+		tokenType = token.FLOAT
 		l.readChar()
+		if !l.readDigits(isDigit) {
+			return tokenType, false
+		}
 	}
-	return string(l.input[position:l.position])
+
+	if l.ch == 'e' || l.ch == 'E' {
+		tokenType = token.FLOAT
+		l.readChar()
+		if l.ch == '+' || l.ch == '-' {
+			l.readChar()
+		}
+		if !isDigit(l.ch) {
+			l.addError(l.pos, "exponent has no digits")
+			return tokenType, false
+		}
+		if !l.readDigits(isDigit) {
+			return tokenType, false
+		}
+	}
+
+	return tokenType, l.checkNumberEnd()
+}
+
+func (l *Lexer) readPrefixedNumber(name string, isValidDigit func(rune) bool) (token.TokenType, bool) {
+	l.readChar() // '0'
+	l.readChar() // prefix
+
+	if !isValidDigit(l.ch) {
+		l.addError(l.pos, "%s literal has no digits", name)
+		return token.INT, false
+	}
+	if !l.readDigits(isValidDigit) {
+		return token.INT, false
+	}
+
+	return token.INT, l.checkNumberEnd()
+}
+
+// readDigits reads digits, which can be separated by single '_'
+func (l *Lexer) readDigits(isValidDigit func(rune) bool) bool {
+	for isValidDigit(l.ch) || l.ch == '_' {
+		if l.ch == '_' && !isValidDigit(l.peekChar()) {
+			l.addError(l.pos, "'_' must separate successive digits")
+			return false
+		}
+		l.readChar()
+	}
+
+	return true
+}
+
+// checkNumberEnd reports a number which is directly followed by letters, digits or another fraction. e.g. 1.2.3, 0b102
+func (l *Lexer) checkNumberEnd() bool {
+	if isLetter(l.ch) || isDigit(l.ch) || (l.ch == '.' && isDigit(l.peekChar())) {
+		l.addError(l.pos, "invalid character %q in number literal", l.ch)
+		return false
+	}
+
+	return true
+}
+
+func (l *Lexer) skipMalformedNumber() {
+	for isLetter(l.ch) || isDigit(l.ch) || l.ch == '.' {
+		l.readChar()
+	}
+}
+
+func isHexDigit(ch rune) bool {
+	_, ok := hexDigitValue(ch)
+	return ok
+}
+
+func isOctalDigit(ch rune) bool {
+	return '0' <= ch && ch <= '7'
+}
+
+func isBinaryDigit(ch rune) bool {
+	return ch == '0' || ch == '1'
 }
 
 func (l *Lexer) peekChar() rune {
@@ -176,17 +333,116 @@ func (l *Lexer) peekChar() rune {
 	}
 }
 
-func (l *Lexer) readString() string {
-	position := l.position + 1
+func (l *Lexer) newStringToken() token.Token {
+	start := l.position
+	startPos := l.pos
+
+	var out strings.Builder
+	valid := true // on bad escape, keep reading until closing quote not to break following tokens
 	for {
 		l.readChar()
-		if l.ch == '"' || l.ch == 0 {
-			break
+
+		switch l.ch {
+		case '"':
+			if !valid {
+				return token.Token{Type: token.ILLEGAL, Literal: string(l.input[start : l.position+1])}
+			}
+			return token.Token{Type: token.STRING, Literal: out.String()}
+		case 0:
+			l.addError(startPos, "unterminated string literal")
+			return token.Token{Type: token.ILLEGAL, Literal: string(l.input[start:l.position])}
+		case '\\':
+			if l.peekChar() == 0 {
+				continue // reported as unterminated string
+			}
+
+			ch, ok := l.readEscape()
+			if !ok {
+				valid = false
+				continue
+			}
+			out.WriteRune(ch)
+		default:
+			out.WriteRune(l.ch)
 		}
+	}
+}
 
+// readEscape decodes escape sequence after backslash, and leaves l.ch on its last character
+func (l *Lexer) readEscape() (rune, bool) {
+	start := l.position
+	startPos := l.pos
+
+	l.readChar()
+	switch l.ch {
+	case 'n':
+		return '\n', true
+	case 't':
+		return '\t', true
+	case 'r':
+		return '\r', true
+	case '0':
+		return 0, true
+	case '"':
+		return '"', true
+	case '\\':
+		return '\\', true
+	case 'x':
+		return l.readHexEscape(start, startPos, 2)
+	case 'u':
+		return l.readHexEscape(start, startPos, 4)
+	default:
+		l.addError(startPos, "unknown escape sequence \\%c", l.ch)
+		return 0, false
 	}
+}
 
-	return string(l.input[position:l.position])
+func (l *Lexer) readHexEscape(start int, startPos token.Position, digits int) (rune, bool) {
+	var value rune
+
+	for i := 0; i < digits; i++ {
+		digit, ok := hexDigitValue(l.peekChar()) // peek not to swallow closing quote
+		if !ok {
+			l.addError(startPos, "invalid escape sequence %s, want %d hex digits", string(l.input[start:l.readPosition]), digits)
+			return 0, false
+		}
+		value = value*16 + digit
+
+		l.readChar()
+	}
+
+	return value, true
+}
+
+func hexDigitValue(ch rune) (rune, bool) {
+	switch {
+	case '0' <= ch && ch <= '9':
+		return ch - '0', true
+	case 'a' <= ch && ch <= 'f':
+		return ch - 'a' + 10, true
+	case 'A' <= ch && ch <= 'F':
+		return ch - 'A' + 10, true
+	default:
+		return 0, false
+	}
+}
+
+// newRawStringToken reads backtick-delimited string, which keeps backslashes and line breaks as-is
+func (l *Lexer) newRawStringToken() token.Token {
+	start := l.position
+	startPos := l.pos
+
+	for {
+		l.readChar()
+
+		switch l.ch {
+		case '`':
+			return token.Token{Type: token.STRING, Literal: string(l.input[start+1 : l.position])}
+		case 0:
+			l.addError(startPos, "unterminated raw string literal")
+			return token.Token{Type: token.ILLEGAL, Literal: string(l.input[start:l.position])}
+		}
+	}
 }
 
 func (l *Lexer) makeTwoCharToken(currChar rune) token.Token {
@@ -2,12 +2,12 @@ package object
 
 import (
 	"bytes"
+	"sort"
 	"strings"
 )
 
 type Array struct {
 	Elements []Object
-	offset   int // This is for for-loop
 }
 
 func (arr *Array) Type() ObjectType { return ARRAY_OBJ }
@@ -46,35 +46,59 @@ func (arr *Array) Equals(o Object) bool {
 	return true
 }
 
-func (arr *Array) HasNext() bool {
-	if arr.offset >= len(arr.Elements) {
-		return false
-	} else {
-		return true
-	}
+func (arr *Array) Iter() Iterator {
+	return &ArrayIterator{array: arr}
 }
-func (arr *Array) Next() (Object, Object, bool) {
-	if arr.HasNext() {
-		idx := &Integer{Value: int64(arr.offset)}
-		val := arr.Elements[arr.offset]
-
-		arr.offset++
 
-		return val, idx, true
-	}
-
-	return &Null{}, &Null{}, false
-}
-func (arr *Array) Reset() {
-	arr.offset = 0
-}
-
-func (arr *Array) Apply(method string, env *Environment, args ...Object) (Object, bool) {
+// Apply calls array method. push, pop, insert, remove, clear, reverse and sort change the array in place,
+// and the others return new values. map, filter, reduce, find, any, all, forEach and sort take functions.
+func (arr *Array) Apply(method string, invoker Invoker, args ...Object) (Object, bool) {
 	switch method {
 	case "isEmpty":
-		return &Boolean{Value: arr.IsEmpty()}, true
+		return NativeBool(arr.IsEmpty()), true
+	case "first":
+		return arr.First(), true
 	case "last":
-		return arr.Last()
+		return arr.Last(), true
+	case "push":
+		return arr.Push(args...), true
+	case "pop":
+		return arr.Pop(args...), true
+	case "insert":
+		return arr.Insert(args...), true
+	case "remove":
+		return arr.Remove(args...), true
+	case "clear":
+		return arr.Clear(args...), true
+	case "indexOf":
+		return arr.IndexOf(args...), true
+	case "contains":
+		if err := checkArgs(args, 1, 1); err != nil {
+			return err, true
+		}
+		return NativeBool(arr.indexOf(args[0]) >= 0), true
+	case "reverse":
+		return arr.Reverse(args...), true
+	case "sort":
+		return arr.Sort(invoker, args...), true
+	case "join":
+		return arr.Join(args...), true
+	case "slice":
+		return arr.Slice(args...), true
+	case "map":
+		return arr.Map(invoker, args...), true
+	case "filter":
+		return arr.Filter(invoker, args...), true
+	case "reduce":
+		return arr.Reduce(invoker, args...), true
+	case "find":
+		return arr.Find(invoker, args...), true
+	case "any":
+		return arr.test(method, true, invoker, args), true
+	case "all":
+		return arr.test(method, false, invoker, args), true
+	case "forEach":
+		return arr.ForEach(invoker, args...), true
 	}
 
 	return nil, false
@@ -86,10 +110,398 @@ func (arr *Array) IsEmpty() bool {
 
 	return false
 }
-func (arr *Array) Last() (Object, bool) {
+
+// First returns the first element, or null if the array is empty
+func (arr *Array) First() Object {
+	if arr.IsEmpty() {
+		return NULL
+	}
+
+	return arr.Elements[0]
+}
+
+// Last returns the last element, or null if the array is empty
+func (arr *Array) Last() Object {
 	if arr.IsEmpty() {
-		return nil, false
+		return NULL
+	}
+
+	return arr.Elements[len(arr.Elements)-1]
+}
+
+// Push adds values at the end
+func (arr *Array) Push(args ...Object) Object {
+	if len(args) == 0 {
+		return newError(TYPE_ERROR, "wrong number of arguments. got=0, want=1 or more")
+	}
+
+	arr.Elements = append(arr.Elements, args...)
+
+	return NULL
+}
+
+// Pop removes and returns the element at index, the default index is the last
+func (arr *Array) Pop(args ...Object) Object {
+	if err := checkArgs(args, 0, 1); err != nil {
+		return err
+	}
+	if arr.IsEmpty() {
+		return newError(INDEX_ERROR, "pop from empty array")
+	}
+
+	idx := int64(len(arr.Elements) - 1)
+	if len(args) == 1 {
+		i, err := intArg("pop", args[0])
+		if err != nil {
+			return err
+		}
+		if i < 0 {
+			i += int64(len(arr.Elements))
+		}
+		if i < 0 || i >= int64(len(arr.Elements)) {
+			return newError(INDEX_ERROR, "pop index out of bound: %s", args[0].Inspect())
+		}
+		idx = i
+	}
+
+	val := arr.Elements[idx]
+	arr.Elements = append(arr.Elements[:idx], arr.Elements[idx+1:]...)
+
+	return val
+}
+
+// Insert inserts value before index like python, index out of the array inserts at the start or the end
+func (arr *Array) Insert(args ...Object) Object {
+	if err := checkArgs(args, 2, 2); err != nil {
+		return err
+	}
+
+	idx, err := intArg("insert", args[0])
+	if err != nil {
+		return err
+	}
+	idx = clampIndex(idx, len(arr.Elements))
+
+	arr.Elements = append(arr.Elements, nil)
+	copy(arr.Elements[idx+1:], arr.Elements[idx:])
+	arr.Elements[idx] = args[1]
+
+	return NULL
+}
+
+// Remove removes the first element which equals value
+func (arr *Array) Remove(args ...Object) Object {
+	if err := checkArgs(args, 1, 1); err != nil {
+		return err
+	}
+
+	idx := arr.indexOf(args[0])
+	if idx < 0 {
+		return newError(VALUE_ERROR, "%s is not in array", inspect(args[0]))
+	}
+	arr.Elements = append(arr.Elements[:idx], arr.Elements[idx+1:]...)
+
+	return NULL
+}
+
+// Clear removes all elements
+func (arr *Array) Clear(args ...Object) Object {
+	if err := checkArgs(args, 0, 0); err != nil {
+		return err
+	}
+
+	arr.Elements = []Object{}
+
+	return NULL
+}
+
+// IndexOf returns index of the first element which equals value, or -1
+func (arr *Array) IndexOf(args ...Object) Object {
+	if err := checkArgs(args, 1, 1); err != nil {
+		return err
+	}
+
+	return &Integer{Value: int64(arr.indexOf(args[0]))}
+}
+
+func (arr *Array) indexOf(val Object) int {
+	for i, element := range arr.Elements {
+		if equals(element, val) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Reverse reverses the array in place, and returns the array
+func (arr *Array) Reverse(args ...Object) Object {
+	if err := checkArgs(args, 0, 0); err != nil {
+		return err
+	}
+
+	for i, j := 0, len(arr.Elements)-1; i < j; i, j = i+1, j-1 {
+		arr.Elements[i], arr.Elements[j] = arr.Elements[j], arr.Elements[i]
+	}
+
+	return arr
+}
+
+// Sort sorts the array in place stably, and returns the array. Without comparator, numbers or strings are sorted
+// in ascending order. comparator(a, b) returns negative number if a comes before b, positive number if b comes before a.
+// The array is not changed if sorting fails.
+func (arr *Array) Sort(invoker Invoker, args ...Object) Object {
+	if err := checkArgs(args, 0, 1); err != nil {
+		return err
+	}
+
+	less := func(a, b Object) (bool, *Error) {
+		res, ok := compare(a, b)
+		if !ok {
+			return false, newError(TYPE_ERROR, "can't compare %s and %s", typeOf(a), typeOf(b))
+		}
+		return res < 0, nil
+	}
+	if len(args) == 1 {
+		less = func(a, b Object) (bool, *Error) {
+			res := invoker.Invoke(args[0], a, b)
+			if err, ok := res.(*Error); ok {
+				return false, err
+			}
+			sign, ok := numberSign(res)
+			if !ok {
+				return false, newError(TYPE_ERROR, "comparator must return number, got %s", typeOf(res))
+			}
+			return sign < 0, nil
+		}
+	}
+
+	// the comparator may change the array, so a copy is sorted
+	elements := arr.snapshot()
+	var sortErr *Error
+	sort.SliceStable(elements, func(i, j int) bool {
+		if sortErr != nil {
+			return false
+		}
+		res, err := less(elements[i], elements[j])
+		sortErr = err
+		return res
+	})
+	if sortErr != nil {
+		return sortErr
+	}
+	arr.Elements = elements
+
+	return arr
+}
+
+// Join concatenates elements with separator, the default separator is ","
+func (arr *Array) Join(args ...Object) Object {
+	if err := checkArgs(args, 0, 1); err != nil {
+		return err
+	}
+
+	sep := ","
+	if len(args) == 1 {
+		str, err := stringArg("join", args[0])
+		if err != nil {
+			return err
+		}
+		sep = str
+	}
+
+	parts := make([]string, 0, len(arr.Elements))
+	for _, element := range arr.Elements {
+		parts = append(parts, inspect(element))
+	}
+
+	return &String{Value: strings.Join(parts, sep)}
+}
+
+// Slice returns a new array of elements from start to end, negative index counts from the end
+func (arr *Array) Slice(args ...Object) Object {
+	if err := checkArgs(args, 1, 2); err != nil {
+		return err
+	}
+
+	start, err := intArg("slice", args[0])
+	if err != nil {
+		return err
+	}
+	end := int64(len(arr.Elements))
+	if len(args) == 2 {
+		end, err = intArg("slice", args[1])
+		if err != nil {
+			return err
+		}
+	}
+
+	first, last := clampIndex(start, len(arr.Elements)), clampIndex(end, len(arr.Elements))
+	if first >= last {
+		return &Array{Elements: []Object{}}
+	}
+
+	return &Array{Elements: append([]Object{}, arr.Elements[first:last]...)}
+}
+
+// Map returns a new array of results of function for elements
+func (arr *Array) Map(invoker Invoker, args ...Object) Object {
+	if err := checkFuncArg("map", args, 1, 1); err != nil {
+		return err
+	}
+
+	elements := make([]Object, 0, len(arr.Elements))
+	for _, element := range arr.snapshot() {
+		res := invoker.Invoke(args[0], element)
+		if err, ok := res.(*Error); ok {
+			return err
+		}
+		if res == nil { // void function
+			res = NULL
+		}
+		elements = append(elements, res)
+	}
+
+	return &Array{Elements: elements}
+}
+
+// Filter returns a new array of elements for which function returns true
+func (arr *Array) Filter(invoker Invoker, args ...Object) Object {
+	if err := checkFuncArg("filter", args, 1, 1); err != nil {
+		return err
+	}
+
+	elements := []Object{}
+	for _, element := range arr.snapshot() {
+		res := invoker.Invoke(args[0], element)
+		if err, ok := res.(*Error); ok {
+			return err
+		}
+		if isTruthy(res) {
+			elements = append(elements, element)
+		}
+	}
+
+	return &Array{Elements: elements}
+}
+
+// Reduce accumulates elements with function(accumulator, element) from left to right.
+// Without initial value, the first element is the initial value.
+func (arr *Array) Reduce(invoker Invoker, args ...Object) Object {
+	if err := checkFuncArg("reduce", args, 1, 2); err != nil {
+		return err
+	}
+
+	elements := arr.snapshot()
+	var acc Object
+	if len(args) == 2 {
+		acc = args[1]
+	} else {
+		if len(elements) == 0 {
+			return newError(TYPE_ERROR, "reduce of empty array with no initial value")
+		}
+		acc = elements[0]
+		elements = elements[1:]
+	}
+
+	for _, element := range elements {
+		acc = invoker.Invoke(args[0], acc, element)
+		if err, ok := acc.(*Error); ok {
+			return err
+		}
+		if acc == nil { // void function
+			acc = NULL
+		}
+	}
+
+	return acc
+}
+
+// Find returns the first element for which function returns true, or null
+func (arr *Array) Find(invoker Invoker, args ...Object) Object {
+	if err := checkFuncArg("find", args, 1, 1); err != nil {
+		return err
+	}
+
+	for _, element := range arr.snapshot() {
+		res := invoker.Invoke(args[0], element)
+		if err, ok := res.(*Error); ok {
+			return err
+		}
+		if isTruthy(res) {
+			return element
+		}
+	}
+
+	return NULL
+}
+
+// test returns whether function returns the expected result for any element, or doesn't return it for all elements.
+// Without function, elements themselves are tested.
+func (arr *Array) test(method string, expected bool, invoker Invoker, args []Object) Object {
+	if err := checkFuncArg(method, args, 0, 1); err != nil {
+		return err
+	}
+
+	for _, res := range arr.snapshot() {
+		if len(args) == 1 {
+			res = invoker.Invoke(args[0], res)
+			if err, ok := res.(*Error); ok {
+				return err
+			}
+		}
+		if isTruthy(res) == expected {
+			return NativeBool(expected)
+		}
+	}
+
+	return NativeBool(!expected)
+}
+
+// ForEach calls function for each element
+func (arr *Array) ForEach(invoker Invoker, args ...Object) Object {
+	if err := checkFuncArg("forEach", args, 1, 1); err != nil {
+		return err
+	}
+
+	for _, element := range arr.snapshot() {
+		if err, ok := invoker.Invoke(args[0], element).(*Error); ok {
+			return err
+		}
+	}
+
+	return NULL
+}
+
+// snapshot returns a copy of elements. Methods which call functions iterate over it,
+// so the functions can change the array without changing the iteration.
+func (arr *Array) snapshot() []Object {
+	return append([]Object{}, arr.Elements...)
+}
+
+// clampIndex returns index in [0, length], negative index counts from the end
+func clampIndex(idx int64, length int) int64 {
+	if idx < 0 {
+		idx += int64(length)
+	}
+	if idx < 0 {
+		return 0
+	}
+	if idx > int64(length) {
+		return int64(length)
+	}
+
+	return idx
+}
+
+// checkFuncArg checks the number of arguments, and the first argument which is function
+func checkFuncArg(method string, args []Object, min, max int) *Error {
+	if err := checkArgs(args, min, max); err != nil {
+		return err
+	}
+	if len(args) > 0 && args[0] == nil {
+		return newError(TYPE_ERROR, "argument to %s must be function, got %s", method, NULL.Type())
 	}
 
-	return arr.Elements[len(arr.Elements)-1], true
+	return nil
 }
@@ -3,6 +3,7 @@ package object
 type Environment struct {
 	store map[string]Object
 	outer *Environment
+	yield func(Object) bool // set in the environment of generator call
 }
 
 func NewEnclosedEnvironment(outer *Environment) *Environment {
@@ -29,16 +30,31 @@ func (e *Environment) SetInner(name string, val Object) Object {
 	return val
 }
 
+// Set updates the variable in the environment where it is defined, otherwise defines it in this environment
 func (e *Environment) Set(name string, val Object) Object {
-	if e.outer != nil {
-		_, ok := e.outer.Get(name)
-		if ok {
-			e.outer.SetInner(name, val)
-
-			return val
+	for env := e; env != nil; env = env.outer {
+		if _, ok := env.store[name]; ok {
+			return env.SetInner(name, val)
 		}
 	}
 
 	e.store[name] = val
 	return val
 }
+
+// SetYield makes e the environment of generator call, yield in the generator calls fn.
+// fn returns false if the generator is closed.
+func (e *Environment) SetYield(fn func(Object) bool) {
+	e.yield = fn
+}
+
+// Yielder returns the yield function of the innermost generator call, or nil outside generator
+func (e *Environment) Yielder() func(Object) bool {
+	for env := e; env != nil; env = env.outer {
+		if env.yield != nil {
+			return env.yield
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package object
+
+import (
+	"fmt"
+	"pythia/token"
+)
+
+type ErrorKind string
+
+const (
+	ERROR           ErrorKind = "Error"
+	TYPE_ERROR      ErrorKind = "TypeError"
+	VALUE_ERROR     ErrorKind = "ValueError"
+	INDEX_ERROR     ErrorKind = "IndexError"
+	KEY_ERROR       ErrorKind = "KeyError"
+	NAME_ERROR      ErrorKind = "NameError"
+	ATTRIBUTE_ERROR ErrorKind = "AttributeError"
+	IMPORT_ERROR    ErrorKind = "ImportError"
+
+	ZERO_DIVISION_ERROR ErrorKind = "ZeroDivisionError"
+	STOP_ITERATION      ErrorKind = "StopIteration" // raised by next of exhausted iterator
+)
+
+// Error is a raised error. It stops evaluation until it is caught by try-catch.
+type Error struct {
+	Kind    ErrorKind
+	Message string
+	Pos     token.Position // where the error is raised
+	Stack   []Frame        // function calls which the error is passed through, innermost first
+}
+
+// Frame is a location in a function. For Error.Stack, Function is the called function and Pos is the call site.
+type Frame struct {
+	Function string
+	Pos      token.Position
+}
+
+const (
+	MODULE_FRAME    = "<module>"    // top level of script or REPL input
+	ANONYMOUS_FRAME = "<anonymous>" // function literal without name
+)
+
+func (f Frame) String() string {
+	if f.Pos.Filename != "" {
+		return fmt.Sprintf("File %q, line %d, in %s", f.Pos.Filename, f.Pos.Line, f.Function)
+	}
+
+	return fmt.Sprintf("line %d, in %s", f.Pos.Line, f.Function)
+}
+
+func newError(kind ErrorKind, format string, a ...interface{}) *Error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, a...)}
+}
+
+func (e *Error) Type() ObjectType { return ERROR_OBJ }
+func (e *Error) Inspect() string  { return string(e.Kind) + ": " + e.Message }
+func (e *Error) Equals(o Object) bool {
+	obj, ok := o.(*Error)
+	if !ok {
+		return false
+	}
+
+	return e.Kind == obj.Kind && e.Message == obj.Message
+}
+
+// Traceback returns the positions from the outermost call to where the error is raised,
+// with the function which each position is in.
+func (e *Error) Traceback() []Frame {
+	frames := make([]Frame, 0, len(e.Stack)+1)
+
+	caller := MODULE_FRAME
+	for i := len(e.Stack) - 1; i >= 0; i-- {
+		frames = append(frames, Frame{Function: caller, Pos: e.Stack[i].Pos})
+		caller = e.Stack[i].Function
+	}
+
+	if e.Pos.IsValid() {
+		frames = append(frames, Frame{Function: caller, Pos: e.Pos})
+	}
+
+	return frames
+}
+
+// Exception is a caught error, or an error created by error builtin function.
+// Unlike Error, it is a plain value, and raised again only by throw.
+type Exception struct {
+	Err *Error
+}
+
+func (ex *Exception) Type() ObjectType { return EXCEPTION_OBJ }
+func (ex *Exception) Inspect() string  { return ex.Err.Inspect() }
+func (ex *Exception) Equals(o Object) bool {
+	obj, ok := o.(*Exception)
+	if !ok {
+		return false
+	}
+
+	return ex.Err.Equals(obj.Err)
+}
+
+func (ex *Exception) Apply(method string, invoker Invoker, args ...Object) (Object, bool) {
+	switch method {
+	case "message":
+		return &String{Value: ex.Err.Message}, true
+	case "kind":
+		return &String{Value: string(ex.Err.Kind)}, true
+	case "trace":
+		return ex.traceArray(), true
+	}
+
+	return nil, false
+}
+
+// traceArray returns traceback of error as strings, the outermost call first
+func (ex *Exception) traceArray() *Array {
+	frames := ex.Err.Traceback()
+
+	elements := make([]Object, 0, len(frames))
+	for _, frame := range frames {
+		elements = append(elements, &String{Value: frame.String()})
+	}
+
+	return &Array{Elements: elements}
+}
@@ -7,10 +7,11 @@ import (
 )
 
 type Function struct {
-	Parameters []*ast.Identifier
-	Name       *ast.Identifier
-	Body       *ast.BlockStatement
-	Env        *Environment
+	Parameters  []*ast.Identifier
+	Name        *ast.Identifier
+	Body        *ast.BlockStatement
+	Env         *Environment
+	IsGenerator bool // calling it returns Generator instead of running body
 }
 
 func (f *Function) Type() ObjectType { return FUNCTION_OBJ }
@@ -22,8 +23,10 @@ func (f *Function) Inspect() string {
 		params = append(params, p.String())
 	}
 
-	out.WriteString("func ")
-	out.WriteString(f.Name.String())
+	out.WriteString("func")
+	if f.Name != nil { // anonymous function doesn't have name
+		out.WriteString(" " + f.Name.String())
+	}
 	out.WriteString("(")
 	out.WriteString(strings.Join(params, ", "))
 	out.WriteString(") {\n")
@@ -41,7 +44,8 @@ func (f *Function) Equals(o Object) bool {
 	return f == obj
 }
 
-type BuiltinFunction func(args ...Object) Object
+// BuiltinFunction is a function implemented in Go. invoker calls functions given as arguments.
+type BuiltinFunction func(invoker Invoker, args ...Object) Object
 
 type Builtin struct {
 	Fn BuiltinFunction
@@ -10,9 +10,107 @@ type HashPair struct {
 	Key   Object
 	Value Object
 }
+
+// hashEntry is a node of the insertion order list. A removed entry keeps its next,
+// so that iteration which stopped at it can go on.
+type hashEntry struct {
+	pair    HashPair
+	key     HashKey
+	prev    *hashEntry
+	next    *hashEntry
+	order   uint64 // insertion number, it increases along the list
+	removed bool
+}
+
+// Hash keeps pairs in insertion order like Python dict. Updating a value keeps the position of the key.
 type Hash struct {
-	Pairs map[HashKey]HashPair
-	check map[HashKey]struct{} // This is for for-loop
+	entries map[HashKey]*hashEntry
+	head    *hashEntry
+	tail    *hashEntry
+	added   uint64 // the number of inserted entries
+}
+
+func NewHash() *Hash {
+	return &Hash{entries: map[HashKey]*hashEntry{}}
+}
+
+// Get returns the pair of key
+func (h *Hash) Get(key HashKey) (HashPair, bool) {
+	entry, ok := h.entries[key]
+	if !ok {
+		return HashPair{}, false
+	}
+
+	return entry.pair, true
+}
+
+// Set adds pair at the end, or replaces the value if key exists already
+func (h *Hash) Set(key HashKey, pair HashPair) {
+	if entry, ok := h.entries[key]; ok {
+		entry.pair.Value = pair.Value
+		return
+	}
+
+	if h.entries == nil {
+		h.entries = map[HashKey]*hashEntry{}
+	}
+
+	h.added++
+	entry := &hashEntry{pair: pair, key: key, prev: h.tail, order: h.added}
+	if h.tail == nil {
+		h.head = entry
+	} else {
+		h.tail.next = entry
+	}
+	h.tail = entry
+	h.entries[key] = entry
+}
+
+// Delete removes key, and returns false if there is no key
+func (h *Hash) Delete(key HashKey) bool {
+	entry, ok := h.entries[key]
+	if !ok {
+		return false
+	}
+
+	if entry.prev == nil {
+		h.head = entry.next
+	} else {
+		entry.prev.next = entry.next
+	}
+	if entry.next == nil {
+		h.tail = entry.prev
+	} else {
+		entry.next.prev = entry.prev
+	}
+	entry.removed = true
+	delete(h.entries, key)
+
+	return true
+}
+
+// after returns the first entry inserted after order. Entries added later are at the end, so it searches from the tail.
+func (h *Hash) after(order uint64) *hashEntry {
+	var entry *hashEntry
+	for e := h.tail; e != nil && e.order > order; e = e.prev {
+		entry = e
+	}
+
+	return entry
+}
+
+func (h *Hash) Len() int {
+	return len(h.entries)
+}
+
+// Pairs returns pairs in insertion order
+func (h *Hash) Pairs() []HashPair {
+	pairs := make([]HashPair, 0, len(h.entries))
+	for entry := h.head; entry != nil; entry = entry.next {
+		pairs = append(pairs, entry.pair)
+	}
+
+	return pairs
 }
 
 func (h *Hash) Type() ObjectType { return HASH_OBJ }
@@ -20,7 +118,7 @@ func (h *Hash) Inspect() string {
 	var out bytes.Buffer
 
 	pairs := []string{}
-	for _, pair := range h.Pairs {
+	for _, pair := range h.Pairs() {
 		pairs = append(pairs, fmt.Sprintf("%s: %s", pair.Key.Inspect(), pair.Value.Inspect()))
 	}
 
@@ -30,23 +128,21 @@ func (h *Hash) Inspect() string {
 
 	return out.String()
 }
+
+// Equals compares pairs regardless of order
 func (h *Hash) Equals(o Object) bool {
 	obj, ok := o.(*Hash)
 	if !ok {
 		return false
 	}
 
-	if len(h.Pairs) != len(obj.Pairs) {
+	if h.Len() != obj.Len() {
 		return false
 	}
 
-	for pairKey, pairValue := range h.Pairs {
-		if !obj.Pairs[pairKey].Value.Equals(pairValue.Value) {
-			return false
-		}
-	}
-	for pairKey, pairValue := range obj.Pairs {
-		if !h.Pairs[pairKey].Value.Equals(pairValue.Value) {
+	for key, entry := range h.entries {
+		other, ok := obj.entries[key]
+		if !ok || !entry.pair.Value.Equals(other.pair.Value) {
 			return false
 		}
 	}
@@ -54,34 +150,14 @@ func (h *Hash) Equals(o Object) bool {
 	return true
 }
 
-func (h *Hash) HasNext() bool {
-	if len(h.check) >= len(h.Pairs) {
-		return false
-	} else {
-		return true
-	}
-}
-func (h *Hash) Next() (Object, Object, bool) {
-	if h.HasNext() {
-		for k, pair := range h.Pairs {
-			if _, isContained := h.check[k]; !isContained {
-				h.check[k] = struct{}{}
-
-				return pair.Key, pair.Value, true
-			}
-		}
-	}
-
-	return &Null{}, &Null{}, false
-}
-func (h *Hash) Reset() {
-	h.check = map[HashKey]struct{}{}
+func (h *Hash) Iter() Iterator {
+	return &HashIterator{hash: h}
 }
 
-func (h *Hash) Apply(method string, env *Environment, args ...Object) (Object, bool) {
+func (h *Hash) Apply(method string, invoker Invoker, args ...Object) (Object, bool) {
 	switch method {
 	case "isEmpty":
-		return &Boolean{Value: h.IsEmpty()}, true
+		return NativeBool(h.IsEmpty()), true
 	case "keys":
 		return h.Keys()
 	case "values":
@@ -91,30 +167,24 @@ func (h *Hash) Apply(method string, env *Environment, args ...Object) (Object, b
 	return nil, false
 }
 func (h *Hash) IsEmpty() bool {
-	if len(h.Pairs) == 0 {
+	if h.Len() == 0 {
 		return true
 	}
 
 	return false
 }
 func (h *Hash) Keys() (Object, bool) {
-	elements := make([]Object, len(h.Pairs))
-
-	i := 0
-	for _, pair := range h.Pairs {
-		elements[i] = pair.Key
-		i++
+	elements := make([]Object, 0, h.Len())
+	for _, pair := range h.Pairs() {
+		elements = append(elements, pair.Key)
 	}
 
 	return &Array{Elements: elements}, true
 }
 func (h *Hash) Values() (Object, bool) {
-	elements := make([]Object, len(h.Pairs))
-
-	i := 0
-	for _, pair := range h.Pairs {
-		elements[i] = pair.Value
-		i++
+	elements := make([]Object, 0, h.Len())
+	for _, pair := range h.Pairs() {
+		elements = append(elements, pair.Value)
 	}
 
 	return &Array{Elements: elements}, true
@@ -0,0 +1,170 @@
+package object
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// ArrayIterator yields element and index of array. Elements appended while iterating are visited too.
+type ArrayIterator struct {
+	array  *Array
+	offset int
+}
+
+func (it *ArrayIterator) Type() ObjectType { return ITERATOR_OBJ }
+func (it *ArrayIterator) Inspect() string  { return fmt.Sprintf("<array iterator at %d>", it.offset) }
+func (it *ArrayIterator) Equals(o Object) bool {
+	obj, ok := o.(*ArrayIterator)
+	return ok && it == obj
+}
+func (it *ArrayIterator) Next() (Object, Object, bool) {
+	if it.offset >= len(it.array.Elements) {
+		return NULL, NULL, false
+	}
+
+	idx := &Integer{Value: int64(it.offset)}
+	val := it.array.Elements[it.offset]
+	it.offset++
+
+	return val, idx, true
+}
+
+// StringIterator yields character and index of string. Characters are Unicode code points,
+// and index counts characters, not bytes.
+type StringIterator struct {
+	str    *String
+	offset int // byte offset of the next character
+	index  int
+}
+
+func (it *StringIterator) Type() ObjectType { return ITERATOR_OBJ }
+func (it *StringIterator) Inspect() string  { return fmt.Sprintf("<string iterator at %d>", it.index) }
+func (it *StringIterator) Equals(o Object) bool {
+	obj, ok := o.(*StringIterator)
+	return ok && it == obj
+}
+func (it *StringIterator) Next() (Object, Object, bool) {
+	if it.offset >= len(it.str.Value) {
+		return NULL, NULL, false
+	}
+
+	ch, size := utf8.DecodeRuneInString(it.str.Value[it.offset:])
+	idx := &Integer{Value: int64(it.index)}
+	val := &String{Value: string(ch)}
+	it.offset += size
+	it.index++
+
+	return val, idx, true
+}
+
+// RangeIterator yields element and index of range
+type RangeIterator struct {
+	rng    *Range
+	offset uint64
+}
+
+func (it *RangeIterator) Type() ObjectType { return ITERATOR_OBJ }
+func (it *RangeIterator) Inspect() string  { return fmt.Sprintf("<range iterator at %d>", it.offset) }
+func (it *RangeIterator) Equals(o Object) bool {
+	obj, ok := o.(*RangeIterator)
+	return ok && it == obj
+}
+func (it *RangeIterator) Next() (Object, Object, bool) {
+	if it.offset >= it.rng.Len() {
+		return NULL, NULL, false
+	}
+
+	idx := &Integer{Value: int64(it.offset)}
+	val := &Integer{Value: it.rng.At(it.offset)}
+	it.offset++
+
+	return val, idx, true
+}
+
+// HashIterator yields key and value of hash in insertion order.
+// Removed keys are skipped, and keys added while iterating are visited.
+type HashIterator struct {
+	hash    *Hash
+	cursor  *hashEntry // the entry returned last
+	started bool
+}
+
+func (it *HashIterator) Type() ObjectType { return ITERATOR_OBJ }
+func (it *HashIterator) Inspect() string  { return "<hash iterator>" }
+func (it *HashIterator) Equals(o Object) bool {
+	obj, ok := o.(*HashIterator)
+	return ok && it == obj
+}
+func (it *HashIterator) Next() (Object, Object, bool) {
+	var entry *hashEntry
+	if !it.started {
+		entry = it.hash.head
+		it.started = true
+	} else if it.cursor != nil {
+		entry = it.cursor.next
+		for entry != nil && entry.removed {
+			entry = entry.next
+		}
+		// removed entries may end at an old tail, and keys added after it are not linked from them
+		if entry == nil && it.cursor.removed {
+			entry = it.hash.after(it.cursor.order)
+		}
+	}
+
+	it.cursor = entry
+	if entry == nil {
+		return NULL, NULL, false
+	}
+
+	return entry.pair.Key, entry.pair.Value, true
+}
+
+// Generator is an iterator which runs a function step by step, e.g. generator function or `next` of user-defined iterator.
+// Engines provide Step, so generator of both tree-walking evaluator and vm works the same.
+type Generator struct {
+	Name string                // name of the function, error raised in it records the name
+	Step func() (Object, bool) // runs the function until the next value. false if it is finished, the value is *Error if it raises error
+
+	index   int
+	running bool
+	done    bool
+}
+
+func (g *Generator) Type() ObjectType { return GENERATOR_OBJ }
+func (g *Generator) Inspect() string  { return fmt.Sprintf("<generator %s>", g.Name) }
+func (g *Generator) Equals(o Object) bool {
+	obj, ok := o.(*Generator)
+	return ok && g == obj
+}
+
+// Next yields the next value and the number of values yielded before it.
+// Error raised in the function is yielded once, and it records the function without position,
+// then the engine sets the position where the generator is resumed.
+func (g *Generator) Next() (Object, Object, bool) {
+	if g.done {
+		return NULL, NULL, false
+	}
+	if g.running {
+		return &Error{Kind: VALUE_ERROR, Message: "generator already executing"}, NULL, true
+	}
+
+	g.running = true
+	val, ok := g.Step()
+	g.running = false
+
+	if !ok {
+		g.done = true
+		return NULL, NULL, false
+	}
+
+	if err, isError := val.(*Error); isError {
+		g.done = true
+		err.Stack = append(err.Stack, Frame{Function: g.Name})
+		return err, NULL, true
+	}
+
+	idx := &Integer{Value: int64(g.index)}
+	g.index++
+
+	return val, idx, true
+}
@@ -0,0 +1,26 @@
+package object
+
+import "fmt"
+
+// Module is an evaluated source file. Its top-level bindings are accessed with `.`
+type Module struct {
+	Name string
+	Path string // absolute path of source file
+	Env  *Environment
+}
+
+func (m *Module) Type() ObjectType { return MODULE_OBJ }
+func (m *Module) Inspect() string  { return fmt.Sprintf("<module %s from %q>", m.Name, m.Path) }
+func (m *Module) Equals(o Object) bool {
+	obj, ok := o.(*Module)
+	if !ok {
+		return false
+	}
+
+	return m == obj
+}
+
+// Get returns the top-level binding of module
+func (m *Module) Get(name string) (Object, bool) {
+	return m.Env.Get(name)
+}
@@ -2,7 +2,10 @@ package object
 
 import (
 	"fmt"
+	"hash/fnv"
 	"math"
+	"math/big"
+	"strings"
 )
 
 type Number interface {
@@ -35,6 +38,58 @@ func (i *Integer) Equals(o Object) bool {
 func (i *Integer) Number()            {}
 func (i *Integer) ToFloat64() float64 { return float64(i.Value) }
 
+// BigInt is an integer out of int64 range. It is the same INTEGER type as Integer,
+// integer operations promote to BigInt on overflow and results in int64 range are Integer again.
+type BigInt struct {
+	Value *big.Int
+}
+
+func (b *BigInt) Inspect() string  { return b.Value.String() }
+func (b *BigInt) Type() ObjectType { return INTEGER_OBJ }
+func (b *BigInt) HashKey() HashKey {
+	h := fnv.New64a()
+	if b.Value.Sign() < 0 {
+		h.Write([]byte{'-'})
+	}
+	h.Write(b.Value.Bytes())
+
+	return HashKey{Type: BIGINT_KEY, Value: h.Sum64()}
+}
+func (b *BigInt) Equals(o Object) bool {
+	obj, ok := o.(*BigInt)
+	if !ok {
+		return false
+	}
+
+	return b.Value.Cmp(obj.Value) == 0
+}
+func (b *BigInt) Number() {}
+func (b *BigInt) ToFloat64() float64 {
+	f, _ := new(big.Float).SetInt(b.Value).Float64()
+	return f
+}
+
+// NewInteger returns Integer if value is in int64 range, otherwise BigInt
+func NewInteger(value *big.Int) Object {
+	if value.IsInt64() {
+		return &Integer{Value: value.Int64()}
+	}
+
+	return &BigInt{Value: value}
+}
+
+// ToBigInt returns the value of Integer or BigInt as big.Int
+func ToBigInt(obj Object) (*big.Int, bool) {
+	switch obj := obj.(type) {
+	case *Integer:
+		return big.NewInt(obj.Value), true
+	case *BigInt:
+		return obj.Value, true
+	default:
+		return nil, false
+	}
+}
+
 type Float struct {
 	Value float64
 }
@@ -54,3 +109,65 @@ func (f *Float) Equals(o Object) bool {
 }
 func (f *Float) Number()            {}
 func (f *Float) ToFloat64() float64 { return f.Value }
+
+// compare returns negative, zero or positive number as a is less than, equal to or greater than b.
+// Numbers are compared with numbers, and strings with strings. It returns false for the other types.
+func compare(a, b Object) (int, bool) {
+	if a, ok := a.(*String); ok {
+		if b, ok := b.(*String); ok {
+			return strings.Compare(a.Value, b.Value), true
+		}
+		return 0, false
+	}
+
+	if x, ok := a.(*Integer); ok {
+		if y, ok := b.(*Integer); ok {
+			switch {
+			case x.Value < y.Value:
+				return -1, true
+			case x.Value > y.Value:
+				return 1, true
+			default:
+				return 0, true
+			}
+		}
+	}
+
+	if x, ok := ToBigInt(a); ok {
+		if y, ok := ToBigInt(b); ok {
+			return x.Cmp(y), true
+		}
+	}
+
+	x, ok := a.(Real)
+	if !ok {
+		return 0, false
+	}
+	y, ok := b.(Real)
+	if !ok {
+		return 0, false
+	}
+
+	switch xf, yf := x.ToFloat64(), y.ToFloat64(); {
+	case xf < yf:
+		return -1, true
+	case xf > yf:
+		return 1, true
+	default:
+		return 0, true
+	}
+}
+
+// numberSign returns -1, 0 or 1 as the sign of number
+func numberSign(obj Object) (int, bool) {
+	switch obj := obj.(type) {
+	case *Integer:
+		return compare(obj, &Integer{Value: 0})
+	case *BigInt: // never zero
+		return obj.Value.Sign(), true
+	case *Float:
+		return compare(obj, &Float{Value: 0})
+	default:
+		return 0, false
+	}
+}
@@ -1,9 +1,6 @@
 package object
 
-import (
-	"fmt"
-	"hash/fnv"
-)
+import "fmt"
 
 type ObjectType string
 
@@ -13,13 +10,22 @@ const (
 	BOOLEAN_OBJ      = "BOOLEAN"
 	NULL_OBJ         = "NULL"
 	RETURN_VALUE_OBJ = "RETURN_VALUE"
+	BREAK_OBJ        = "BREAK"
+	CONTINUE_OBJ     = "CONTINUE"
 	ERROR_OBJ        = "ERROR"
 	FUNCTION_OBJ     = "FUNCTION"
 	STRING_OBJ       = "STRING"
 	BUILTIN_OBJ      = "BUILTIN"
 	ARRAY_OBJ        = "ARRAY"
+	RANGE_OBJ        = "RANGE"
 	HASH_OBJ         = "HASH"
+	EXCEPTION_OBJ    = "EXCEPTION"
+	MODULE_OBJ       = "MODULE"
 	TYPE_OBJ         = "TYPE"
+	ITERATOR_OBJ     = "ITERATOR"
+	GENERATOR_OBJ    = "GENERATOR"
+
+	BIGINT_KEY = "BIGINT" // type of BigInt hash key, it doesn't collide with hash key of Integer
 )
 
 type Object interface {
@@ -28,14 +34,33 @@ type Object interface {
 	Equals(o Object) bool
 }
 
+// Iterable is a container which for-loop can iterate over
+type Iterable interface {
+	Iter() Iterator // returns new iterator at the start of container
+}
+
+// Iterator is the position of one iteration, every loop has its own iterator
 type Iterator interface {
-	HasNext() bool
-	Next() (Object, Object, bool) // required value, optional value, hasNext
-	Reset()                       // Before new iteration start, reset offset information
+	Object
+	Next() (Object, Object, bool) // required value, optional value, hasNext. required value is *Error if iteration fails
 }
 
+// Invoker calls function of the running engine, so that methods can take functions as arguments.
+// Error raised in the function is returned as *Error.
+type Invoker interface {
+	Invoke(fn Object, args ...Object) Object
+}
+
+// Invocable is a function which runs on its own engine, e.g. closure of VM which is passed to function of module
+type Invocable interface {
+	Object
+	Name() string // name of function in the stack of error
+	Invoke(args ...Object) Object
+}
+
+// Callable has methods. Apply returns false if there is no method.
 type Callable interface {
-	Apply(method string, env *Environment, args ...Object) (Object, bool)
+	Apply(method string, invoker Invoker, args ...Object) (Object, bool)
 }
 
 type Collections interface {
@@ -52,6 +77,21 @@ type HashKey struct {
 	Value uint64
 }
 
+// TRUE, FALSE and NULL are shared by engines and methods, so that they can be compared by identity
+var (
+	NULL  = &Null{}
+	TRUE  = &Boolean{Value: true}
+	FALSE = &Boolean{Value: false}
+)
+
+// NativeBool returns TRUE or FALSE of input
+func NativeBool(input bool) *Boolean {
+	if input {
+		return TRUE
+	}
+	return FALSE
+}
+
 type Boolean struct {
 	Value bool
 }
@@ -109,64 +149,36 @@ func (rv *ReturnValue) Equals(o Object) bool {
 	return rv.Value.Equals(obj.Value)
 }
 
-type Error struct {
-	Message string
+// Break is a signal to stop a loop. Label is empty for the innermost loop.
+type Break struct {
+	Label string
 }
 
-func (e *Error) Type() ObjectType { return ERROR_OBJ }
-func (e *Error) Inspect() string  { return "ERROR: " + e.Message }
-func (e *Error) Equals(o Object) bool {
-	obj, ok := o.(*Error)
+func (b *Break) Type() ObjectType { return BREAK_OBJ }
+func (b *Break) Inspect() string  { return "break" }
+func (b *Break) Equals(o Object) bool {
+	obj, ok := o.(*Break)
 	if !ok {
 		return false
 	}
 
-	return e.Message == obj.Message
+	return b.Label == obj.Label
 }
 
-type String struct {
-	Value  string
-	offset int // This is for for-loop
+// Continue is a signal to skip to the next iteration of a loop. Label is empty for the innermost loop.
+type Continue struct {
+	Label string
 }
 
-func (s *String) Type() ObjectType { return STRING_OBJ }
-func (s *String) Inspect() string  { return s.Value }
-func (s *String) HashKey() HashKey {
-	h := fnv.New64()
-	h.Write([]byte(s.Value))
-
-	return HashKey{Type: s.Type(), Value: h.Sum64()}
-}
-
-func (s *String) Equals(o Object) bool {
-	obj, ok := o.(*String)
+func (c *Continue) Type() ObjectType { return CONTINUE_OBJ }
+func (c *Continue) Inspect() string  { return "continue" }
+func (c *Continue) Equals(o Object) bool {
+	obj, ok := o.(*Continue)
 	if !ok {
 		return false
 	}
 
-	return s.Value == obj.Value
-}
-func (s *String) HasNext() bool {
-	if s.offset >= len(s.Value) {
-		return false
-	} else {
-		return true
-	}
-}
-func (s *String) Next() (Object, Object, bool) {
-	if s.HasNext() {
-		idx := &Integer{Value: int64(s.offset)}
-		val := &String{Value: string(s.Value[s.offset])}
-
-		s.offset++
-
-		return val, idx, true
-	}
-
-	return &Null{}, &Null{}, false
-}
-func (s *String) Reset() {
-	s.offset = 0
+	return c.Label == obj.Label
 }
 
 type Type struct {
@@ -183,3 +195,17 @@ func (t *Type) Equals(o Object) bool {
 
 	return t.InstanceType == obj.InstanceType
 }
+
+// isTruthy reports whether obj is true in condition, only null and false are false
+func isTruthy(obj Object) bool {
+	return obj != NULL && obj != FALSE
+}
+
+// equals compares objects, nil is the result of void function
+func equals(a, b Object) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Equals(b)
+}
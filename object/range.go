@@ -0,0 +1,120 @@
+package object
+
+import (
+	"fmt"
+	"math"
+)
+
+// MAX_RANGE_ELEMENTS is the largest number of elements which a range can be converted to, like the length of repeated string
+const MAX_RANGE_ELEMENTS = math.MaxInt32
+
+// Range is a lazy sequence of integers from Start to Stop (exclusive) by Step.
+// Elements are computed when they are requested, so a range of any length uses constant memory.
+// Step is not 0, and it goes from Start toward Stop.
+type Range struct {
+	Start int64
+	Stop  int64
+	Step  int64
+}
+
+func (r *Range) Type() ObjectType { return RANGE_OBJ }
+func (r *Range) Inspect() string {
+	if r.Step == 1 {
+		return fmt.Sprintf("range(%d, %d)", r.Start, r.Stop)
+	}
+
+	return fmt.Sprintf("range(%d, %d, %d)", r.Start, r.Stop, r.Step)
+}
+
+// Equals compares elements like python, e.g. range(0, 3, 2) equals range(0, 4, 2)
+func (r *Range) Equals(o Object) bool {
+	obj, ok := o.(*Range)
+	if !ok {
+		return false
+	}
+
+	length := r.Len()
+	if length != obj.Len() {
+		return false
+	}
+
+	return length == 0 || (r.Start == obj.Start && (length == 1 || r.Step == obj.Step))
+}
+
+// Len returns the number of elements. It is computed in uint64, because range(-2**63, 2**63-1) has more than 2**63-1 elements.
+func (r *Range) Len() uint64 {
+	switch {
+	case r.Step > 0 && r.Start < r.Stop:
+		return (uint64(r.Stop)-uint64(r.Start)-1)/uint64(r.Step) + 1
+	case r.Step < 0 && r.Start > r.Stop:
+		return (uint64(r.Start)-uint64(r.Stop)-1)/(-uint64(r.Step)) + 1
+	default:
+		return 0
+	}
+}
+
+// At returns the element at index i, i must be less than Len
+func (r *Range) At(i uint64) int64 {
+	// the element is in int64 range, so wrapping arithmetic gives the exact value
+	return int64(uint64(r.Start) + i*uint64(r.Step))
+}
+
+// Contains reports whether val is an element, without iterating
+func (r *Range) Contains(val int64) bool {
+	switch {
+	case r.Step > 0:
+		return r.Start <= val && val < r.Stop && (uint64(val)-uint64(r.Start))%uint64(r.Step) == 0
+	case r.Step < 0:
+		return r.Stop < val && val <= r.Start && (uint64(r.Start)-uint64(val))%(-uint64(r.Step)) == 0
+	default:
+		return false
+	}
+}
+
+// ToArray returns a new array of all elements, or ValueError if the range is too long
+func (r *Range) ToArray() Object {
+	length := r.Len()
+	if length > MAX_RANGE_ELEMENTS {
+		return newError(VALUE_ERROR, "%s is too long to convert to array", r.Inspect())
+	}
+
+	elements := make([]Object, 0, length)
+	for i := uint64(0); i < length; i++ {
+		elements = append(elements, &Integer{Value: r.At(i)})
+	}
+
+	return &Array{Elements: elements}
+}
+
+func (r *Range) Iter() Iterator {
+	return &RangeIterator{rng: r}
+}
+
+func (r *Range) Apply(method string, invoker Invoker, args ...Object) (Object, bool) {
+	switch method {
+	case "isEmpty":
+		return NativeBool(r.IsEmpty()), true
+	case "last":
+		return r.Last(), true
+	case "contains":
+		if err := checkArgs(args, 1, 1); err != nil {
+			return err, true
+		}
+		val, ok := args[0].(*Integer)
+		return NativeBool(ok && r.Contains(val.Value)), true
+	}
+
+	return nil, false
+}
+func (r *Range) IsEmpty() bool {
+	return r.Len() == 0
+}
+
+// Last returns the last element, or null if the range is empty
+func (r *Range) Last() Object {
+	if r.IsEmpty() {
+		return NULL
+	}
+
+	return &Integer{Value: r.At(r.Len() - 1)}
+}
@@ -0,0 +1,469 @@
+package object
+
+import (
+	"hash/fnv"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type String struct {
+	Value string
+}
+
+func (s *String) Type() ObjectType { return STRING_OBJ }
+func (s *String) Inspect() string  { return s.Value }
+func (s *String) HashKey() HashKey {
+	h := fnv.New64()
+	h.Write([]byte(s.Value))
+
+	return HashKey{Type: s.Type(), Value: h.Sum64()}
+}
+
+func (s *String) Equals(o Object) bool {
+	obj, ok := o.(*String)
+	if !ok {
+		return false
+	}
+
+	return s.Value == obj.Value
+}
+
+// Len returns the number of characters, i.e. Unicode code points
+func (s *String) Len() int {
+	return utf8.RuneCountInString(s.Value)
+}
+func (s *String) Iter() Iterator {
+	return &StringIterator{str: s}
+}
+
+// Apply calls string method. Methods return a new string, because string is immutable.
+// Positions and widths are counted in characters, not bytes.
+func (s *String) Apply(method string, invoker Invoker, args ...Object) (Object, bool) {
+	switch method {
+	case "isEmpty":
+		return NativeBool(s.Value == ""), true
+	case "split":
+		return s.Split(args...), true
+	case "join":
+		return s.Join(args...), true
+	case "trim":
+		return s.trim(method, strings.Trim, strings.TrimSpace, args), true
+	case "trimLeft":
+		return s.trim(method, strings.TrimLeft, trimLeftSpace, args), true
+	case "trimRight":
+		return s.trim(method, strings.TrimRight, trimRightSpace, args), true
+	case "upper":
+		return s.convert(strings.ToUpper, args), true
+	case "lower":
+		return s.convert(strings.ToLower, args), true
+	case "replace":
+		return s.Replace(args...), true
+	case "find", "indexOf":
+		return s.Find(args...), true
+	case "startsWith":
+		return s.test(method, strings.HasPrefix, args), true
+	case "endsWith":
+		return s.test(method, strings.HasSuffix, args), true
+	case "contains":
+		return s.test(method, strings.Contains, args), true
+	case "repeat":
+		return s.Repeat(args...), true
+	case "padLeft":
+		return s.pad(method, true, args), true
+	case "padRight":
+		return s.pad(method, false, args), true
+	case "lines":
+		return s.Lines(args...), true
+	case "isDigit":
+		return s.all(unicode.IsDigit, args), true
+	case "isAlpha":
+		return s.all(unicode.IsLetter, args), true
+	case "format":
+		return s.Format(args...), true
+	}
+
+	return nil, false
+}
+
+// Split splits string by separator, or by whitespaces without separator
+func (s *String) Split(args ...Object) Object {
+	if err := checkArgs(args, 0, 1); err != nil {
+		return err
+	}
+
+	var parts []string
+	if len(args) == 0 {
+		parts = strings.Fields(s.Value)
+	} else {
+		sep, err := stringArg("split", args[0])
+		if err != nil {
+			return err
+		}
+		if sep == "" {
+			return newError(VALUE_ERROR, "empty separator")
+		}
+		parts = strings.Split(s.Value, sep)
+	}
+
+	elements := make([]Object, 0, len(parts))
+	for _, part := range parts {
+		elements = append(elements, &String{Value: part})
+	}
+
+	return &Array{Elements: elements}
+}
+
+// Join concatenates strings of iterable with the string as separator, e.g. ", ".join(["a", "b"])
+func (s *String) Join(args ...Object) Object {
+	if err := checkArgs(args, 1, 1); err != nil {
+		return err
+	}
+
+	var iter Iterator
+	switch arg := args[0].(type) {
+	case Iterator:
+		iter = arg
+	case Iterable:
+		iter = arg.Iter()
+	default:
+		return newError(TYPE_ERROR, "argument to join must be iterable, got %s", typeOf(args[0]))
+	}
+
+	parts := []string{}
+	for val, _, ok := iter.Next(); ok; val, _, ok = iter.Next() {
+		if err, isError := val.(*Error); isError {
+			return err
+		}
+		str, isString := val.(*String)
+		if !isString {
+			return newError(TYPE_ERROR, "sequence item %d: expected STRING, got %s", len(parts), typeOf(val))
+		}
+		parts = append(parts, str.Value)
+	}
+
+	return &String{Value: strings.Join(parts, s.Value)}
+}
+
+// trim removes characters in cutset from the string, or whitespaces without cutset
+func (s *String) trim(method string, cut func(string, string) string, space func(string) string, args []Object) Object {
+	if err := checkArgs(args, 0, 1); err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return &String{Value: space(s.Value)}
+	}
+
+	cutset, err := stringArg(method, args[0])
+	if err != nil {
+		return err
+	}
+
+	return &String{Value: cut(s.Value, cutset)}
+}
+
+func trimLeftSpace(s string) string  { return strings.TrimLeftFunc(s, unicode.IsSpace) }
+func trimRightSpace(s string) string { return strings.TrimRightFunc(s, unicode.IsSpace) }
+
+func (s *String) convert(fn func(string) string, args []Object) Object {
+	if err := checkArgs(args, 0, 0); err != nil {
+		return err
+	}
+
+	return &String{Value: fn(s.Value)}
+}
+
+// Replace replaces old with new, 3rd argument is the maximum number of replacements
+func (s *String) Replace(args ...Object) Object {
+	if err := checkArgs(args, 2, 3); err != nil {
+		return err
+	}
+
+	old, err := stringArg("replace", args[0])
+	if err != nil {
+		return err
+	}
+	replacement, err := stringArg("replace", args[1])
+	if err != nil {
+		return err
+	}
+	count := -1 // replaces all
+	if len(args) == 3 {
+		n, err := intArg("replace", args[2])
+		if err != nil {
+			return err
+		}
+		if 0 <= n && n <= math.MaxInt32 {
+			count = int(n)
+		}
+	}
+
+	return &String{Value: strings.Replace(s.Value, old, replacement, count)}
+}
+
+// Find returns the character index of the first substring, or -1 if there is no substring
+func (s *String) Find(args ...Object) Object {
+	if err := checkArgs(args, 1, 1); err != nil {
+		return err
+	}
+
+	sub, err := stringArg("find", args[0])
+	if err != nil {
+		return err
+	}
+
+	i := strings.Index(s.Value, sub)
+	if i < 0 {
+		return &Integer{Value: -1}
+	}
+
+	return &Integer{Value: int64(utf8.RuneCountInString(s.Value[:i]))}
+}
+
+func (s *String) test(method string, fn func(string, string) bool, args []Object) Object {
+	if err := checkArgs(args, 1, 1); err != nil {
+		return err
+	}
+
+	sub, err := stringArg(method, args[0])
+	if err != nil {
+		return err
+	}
+
+	return NativeBool(fn(s.Value, sub))
+}
+
+func (s *String) Repeat(args ...Object) Object {
+	if err := checkArgs(args, 1, 1); err != nil {
+		return err
+	}
+
+	count, err := intArg("repeat", args[0])
+	if err != nil {
+		return err
+	}
+	if count < 0 {
+		return newError(VALUE_ERROR, "negative repeat count: %d", count)
+	}
+	if len(s.Value) > 0 && count > int64(math.MaxInt32/len(s.Value)) {
+		return newError(VALUE_ERROR, "repeated string is too long")
+	}
+
+	return &String{Value: strings.Repeat(s.Value, int(count))}
+}
+
+// pad fills the string with fill character up to width, the default fill character is space
+func (s *String) pad(method string, left bool, args []Object) Object {
+	if err := checkArgs(args, 1, 2); err != nil {
+		return err
+	}
+
+	width, err := intArg(method, args[0])
+	if err != nil {
+		return err
+	}
+	if width > math.MaxInt32 {
+		return newError(VALUE_ERROR, "padded string is too long")
+	}
+	fill := " "
+	if len(args) == 2 {
+		fill, err = stringArg(method, args[1])
+		if err != nil {
+			return err
+		}
+		if utf8.RuneCountInString(fill) != 1 {
+			return newError(TYPE_ERROR, "fill character must be exactly one character, got %q", fill)
+		}
+	}
+
+	n := int(width) - s.Len()
+	if n <= 0 {
+		return &String{Value: s.Value}
+	}
+	if left {
+		return &String{Value: strings.Repeat(fill, n) + s.Value}
+	}
+
+	return &String{Value: s.Value + strings.Repeat(fill, n)}
+}
+
+// Lines splits string at line breaks \n, \r\n and \r. Line breaks are not included in lines.
+func (s *String) Lines(args ...Object) Object {
+	if err := checkArgs(args, 0, 0); err != nil {
+		return err
+	}
+
+	elements := []Object{}
+	rest := s.Value
+	for rest != "" {
+		i := strings.IndexAny(rest, "\r\n")
+		if i < 0 {
+			elements = append(elements, &String{Value: rest})
+			break
+		}
+
+		elements = append(elements, &String{Value: rest[:i]})
+		if rest[i] == '\r' && i+1 < len(rest) && rest[i+1] == '\n' {
+			i++
+		}
+		rest = rest[i+1:]
+	}
+
+	return &Array{Elements: elements}
+}
+
+// all reports whether string is not empty and all characters satisfy fn
+func (s *String) all(fn func(rune) bool, args []Object) Object {
+	if err := checkArgs(args, 0, 0); err != nil {
+		return err
+	}
+
+	for _, ch := range s.Value {
+		if !fn(ch) {
+			return FALSE
+		}
+	}
+
+	return NativeBool(s.Value != "")
+}
+
+// Format replaces fields in braces with arguments like python str.format.
+// `{}` is the next argument, `{0}` is the argument at the index, and `{name}` is the value of key in hash argument.
+// `{{` and `}}` are braces themselves.
+func (s *String) Format(args ...Object) Object {
+	var out strings.Builder
+	f := formatter{args: args}
+
+	v := s.Value
+	for i := 0; i < len(v); i++ {
+		switch v[i] {
+		case '{':
+			if i+1 < len(v) && v[i+1] == '{' {
+				out.WriteByte('{')
+				i++
+				continue
+			}
+
+			end := strings.IndexByte(v[i+1:], '}')
+			if end < 0 {
+				return newError(VALUE_ERROR, "single '{' encountered in format string")
+			}
+
+			val, err := f.field(v[i+1 : i+1+end])
+			if err != nil {
+				return err
+			}
+			out.WriteString(val)
+			i += end + 1
+		case '}':
+			if i+1 < len(v) && v[i+1] == '}' {
+				out.WriteByte('}')
+				i++
+				continue
+			}
+
+			return newError(VALUE_ERROR, "single '}' encountered in format string")
+		default:
+			out.WriteByte(v[i])
+		}
+	}
+
+	return &String{Value: out.String()}
+}
+
+type formatter struct {
+	args   []Object
+	next   int  // index of argument for the next automatic field
+	auto   bool // automatic field numbering is used
+	manual bool // manual field specification is used
+}
+
+func (f *formatter) field(name string) (string, *Error) {
+	if name == "" {
+		if f.manual {
+			return "", newError(VALUE_ERROR, "cannot switch from manual field specification to automatic field numbering")
+		}
+		f.auto = true
+		f.next++
+
+		return f.arg(f.next - 1)
+	}
+
+	if idx, err := strconv.Atoi(name); err == nil {
+		if f.auto {
+			return "", newError(VALUE_ERROR, "cannot switch from automatic field numbering to manual field specification")
+		}
+		f.manual = true
+
+		return f.arg(idx)
+	}
+
+	if len(f.args) > 0 {
+		if hash, ok := f.args[0].(*Hash); ok {
+			if pair, ok := hash.Get((&String{Value: name}).HashKey()); ok {
+				return inspect(pair.Value), nil
+			}
+		}
+	}
+
+	return "", newError(KEY_ERROR, "%s is not exist in format arguments", name)
+}
+
+func (f *formatter) arg(idx int) (string, *Error) {
+	if idx < 0 || idx >= len(f.args) {
+		return "", newError(INDEX_ERROR, "format index %d out of range", idx)
+	}
+
+	return inspect(f.args[idx]), nil
+}
+
+// checkArgs returns error if the number of arguments isn't between min and max
+func checkArgs(args []Object, min, max int) *Error {
+	if min <= len(args) && len(args) <= max {
+		return nil
+	}
+	if min == max {
+		return newError(TYPE_ERROR, "wrong number of arguments. got=%d, want=%d", len(args), min)
+	}
+
+	return newError(TYPE_ERROR, "wrong number of arguments. got=%d, want=%d or %d", len(args), min, max)
+}
+
+func stringArg(method string, arg Object) (string, *Error) {
+	str, ok := arg.(*String)
+	if !ok {
+		return "", newError(TYPE_ERROR, "argument to %s must be STRING, got %s", method, typeOf(arg))
+	}
+
+	return str.Value, nil
+}
+
+func intArg(method string, arg Object) (int64, *Error) {
+	switch arg := arg.(type) {
+	case *Integer:
+		return arg.Value, nil
+	case *BigInt:
+		return 0, newError(VALUE_ERROR, "argument %s is too large", arg.Inspect())
+	default:
+		return 0, newError(TYPE_ERROR, "argument to %s must be INTEGER, got %s", method, typeOf(arg))
+	}
+}
+
+// typeOf returns type of obj, nil is the result of void function
+func typeOf(obj Object) ObjectType {
+	if obj == nil {
+		return NULL_OBJ
+	}
+
+	return obj.Type()
+}
+
+func inspect(obj Object) string {
+	if obj == nil {
+		return "null"
+	}
+
+	return obj.Inspect()
+}
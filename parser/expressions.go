@@ -112,11 +112,31 @@ func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
 	return list
 }
 
+// parseIndexExpression parses `left[index]`, or slice expression `left[start:end:step]` whose bounds are optional
 func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
-	exp := &ast.IndexExpression{Token: p.curToken, Left: left}
+	tok := p.curToken
+
+	var start ast.Expression
+	if !p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		start = p.parseExpression(LOWEST)
+
+		if !p.peekTokenIs(token.COLON) {
+			if !p.expectPeek(token.RBRACKET) {
+				return nil
+			}
+			return &ast.IndexExpression{Token: tok, Left: left, Index: start}
+		}
+	}
+
+	exp := &ast.SliceExpression{Token: tok, Left: left, Start: start}
 
 	p.nextToken()
-	exp.Index = p.parseExpression(LOWEST)
+	exp.End = p.parseSliceBound()
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		exp.Step = p.parseSliceBound()
+	}
 
 	if !p.expectPeek(token.RBRACKET) {
 		return nil
@@ -125,6 +145,16 @@ func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
 	return exp
 }
 
+// parseSliceBound parses bound after `:`, it returns nil if the bound is omitted
+func (p *Parser) parseSliceBound() ast.Expression {
+	if p.peekTokenIs(token.COLON) || p.peekTokenIs(token.RBRACKET) {
+		return nil
+	}
+
+	p.nextToken()
+	return p.parseExpression(LOWEST)
+}
+
 func (p *Parser) parseAssignmentExpression(left ast.Expression) ast.Expression {
 	exp := &ast.AssignmentExpression{Token: p.curToken, Left: left}
 
@@ -146,10 +176,28 @@ func (p *Parser) parseMethodCallExpression(left ast.Expression) ast.Expression {
 
 	methodName := p.parseIdentifier()
 
-	if !p.expectPeek(token.LPAREN) {
-		return nil
+	if !p.peekTokenIs(token.LPAREN) {
+		return &ast.AttributeExpression{Token: exp.Token, Object: left, Name: methodName.(*ast.Identifier)}
 	}
+	p.nextToken()
 	exp.Call = p.parseCallExpression(methodName)
 
 	return exp
 }
+
+func (p *Parser) parseConditionalExpression(condition ast.Expression) ast.Expression {
+	exp := &ast.ConditionalExpression{Token: p.curToken, Condition: condition}
+
+	p.nextToken()
+	exp.Consequence = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.COLON) {
+		return nil
+	}
+
+	// right associative, a ? b : c ? d : e is a ? b : (c ? d : e)
+	p.nextToken()
+	exp.Alternative = p.parseExpression(CONDITIONAL - 1)
+
+	return exp
+}
@@ -1,7 +1,9 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
+	"math/big"
 	"pythia/ast"
 	"pythia/token"
 	"strconv"
@@ -15,8 +17,14 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 	lit := &ast.IntegerLiteral{Token: p.curToken}
 
 	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		if bigValue, ok := new(big.Int).SetString(p.curToken.Literal, 0); ok {
+			lit.Big = bigValue
+			return lit
+		}
+	}
 	if err != nil {
-		msg := fmt.Sprintf("could not parse %q as integer, %s", p.curToken.Literal, p.l.GetErrorInfo())
+		msg := fmt.Sprintf("could not parse %q as integer at %s", p.curToken.Literal, p.curToken.Pos)
 		p.errors = append(p.errors, msg)
 		return nil
 	}
@@ -30,7 +38,7 @@ func (p *Parser) parseFloatLiteral() ast.Expression {
 
 	value, err := strconv.ParseFloat(p.curToken.Literal, 64)
 	if err != nil {
-		msg := fmt.Sprintf("could not parse %q as float, %s", p.curToken.Literal, p.l.GetErrorInfo())
+		msg := fmt.Sprintf("could not parse %q as float at %s", p.curToken.Literal, p.curToken.Pos)
 		p.errors = append(p.errors, msg)
 		return nil
 	}
@@ -71,6 +79,7 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 		value := p.parseExpression(LOWEST)
 
 		hash.Pairs[key] = value
+		hash.Keys = append(hash.Keys, key)
 		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
 			return nil
 		}
@@ -86,3 +95,21 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 func (p *Parser) parseNullLiteral() ast.Expression {
 	return &ast.NullLiteral{Token: p.curToken, Value: p.curToken.Literal}
 }
+
+func (p *Parser) parseFunctionLiteral() ast.Expression {
+	lit := &ast.FunctionLiteral{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	lit.Parameters = p.parseFunctionParameters()
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	lit.Body, lit.IsGenerator = p.parseFunctionBody()
+
+	return lit
+}
@@ -11,6 +11,7 @@ const (
 	_ int = iota
 	LOWEST
 	ASSIGN        // =
+	CONDITIONAL   // a ? b : c
 	LOGICAL_OR    // ||
 	LOGICAL_AND   // &&
 	BITWISE_OR    // |
@@ -33,6 +34,7 @@ var precedences = map[token.TokenType]int{
 	token.ASTERISK_ASSIGN:    ASSIGN,
 	token.SLASH_ASSIGN:       ASSIGN,
 	token.PERCENT_ASSIGN:     ASSIGN,
+	token.QUESTION:           CONDITIONAL,
 	token.LOGICAL_AND:        LOGICAL_AND,
 	token.LOGICAL_OR:         LOGICAL_OR,
 	token.BINARY_OR:          BITWISE_OR,
@@ -65,6 +67,10 @@ type Parser struct {
 	l      *lexer.Lexer
 	errors []string
 
+	loopLabels []string // labels of enclosing loops, "" if loop has no label
+	inFunction bool     // yield is allowed only in function body
+	hasYield   bool     // the innermost function being parsed has yield, so it is a generator
+
 	curToken  token.Token
 	peekToken token.Token
 
@@ -90,6 +96,8 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerPrefix(token.LBRACKET, p.parseArrayLiteral)
 	p.registerPrefix(token.LBRACE, p.parseHashLiteral)
 	p.registerPrefix(token.NULL, p.parseNullLiteral)
+	p.registerPrefix(token.FUNCTION, p.parseFunctionLiteral)
+	p.registerPrefix(token.ILLEGAL, p.parseIllegal)
 
 	p.infixParseFns = make(map[token.TokenType]infixParseFn)
 	p.registerInfix(token.PLUS, p.parseInfixExpression)
@@ -119,12 +127,14 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.SLASH_ASSIGN, p.parseAssignmentExpression)
 	p.registerInfix(token.PERCENT_ASSIGN, p.parseAssignmentExpression)
 	p.registerInfix(token.DOT, p.parseMethodCallExpression)
+	p.registerInfix(token.QUESTION, p.parseConditionalExpression)
 
 	return p
 }
 
 func (p *Parser) Errors() []string {
-	return p.errors
+	errors := append([]string{}, p.l.Errors()...)
+	return append(errors, p.errors...)
 }
 
 func (p *Parser) ParseProgram() *ast.Program {
@@ -142,12 +152,17 @@ func (p *Parser) ParseProgram() *ast.Program {
 }
 
 func (p *Parser) peekError(t token.TokenType) {
-	msg := fmt.Sprintf("expected next token to be %s, got %s instead, %s", t, p.peekToken.Type, p.l.GetErrorInfo())
+	msg := fmt.Sprintf("expected next token to be %s, got %s instead at %s", t, p.peekToken.Type, p.peekToken.Pos)
 	p.errors = append(p.errors, msg)
 }
 
+// parseIllegal skips ILLEGAL token silently, because lexer already reported it
+func (p *Parser) parseIllegal() ast.Expression {
+	return nil
+}
+
 func (p *Parser) noPrefixParserError(t token.TokenType) {
-	msg := fmt.Sprintf("no prefix parse function for %s found, %s", t, p.l.GetErrorInfo())
+	msg := fmt.Sprintf("no prefix parse function for %s found at %s", t, p.curToken.Pos)
 	p.errors = append(p.errors, msg)
 }
 
@@ -11,15 +11,37 @@ func (p *Parser) parseStatement() ast.Statement {
 	case token.LET:
 		return p.parseLetStatement()
 	case token.FUNCTION:
+		if !p.peekTokenIs(token.IDENT) { // anonymous function is an expression
+			return p.parseExpressionStatement()
+		}
 		return p.parseFunctionStatement()
 	case token.RETURN:
 		return p.parseReturnStatement()
+	case token.YIELD:
+		return p.parseYieldStatement()
 	case token.IF:
 		return p.parseIfStatement()
 	case token.DOT:
 		return p.parseInstructionStatement()
 	case token.FOR:
-		return p.parseForStatement()
+		return p.parseForStatement(nil)
+	case token.WHILE:
+		return p.parseWhileStatement(nil)
+	case token.BREAK, token.CONTINUE:
+		return p.parseBranchStatement()
+	case token.TRY:
+		return p.parseTryStatement()
+	case token.THROW:
+		return p.parseThrowStatement()
+	case token.IMPORT:
+		return p.parseImportStatement()
+	case token.FROM:
+		return p.parseFromImportStatement()
+	case token.IDENT:
+		if p.peekTokenIs(token.COLON) {
+			return p.parseLabeledStatement()
+		}
+		return p.parseExpressionStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
@@ -68,11 +90,23 @@ func (p *Parser) parseFunctionStatement() *ast.FunctionStatement {
 		return nil
 	}
 
-	lit.Body = p.parseBlockStatement()
+	lit.Body, lit.IsGenerator = p.parseFunctionBody()
 
 	return lit
 }
 
+// parseFunctionBody parses block statement, in which break and continue of outer loops are not allowed.
+// It reports whether the body has yield.
+func (p *Parser) parseFunctionBody() (*ast.BlockStatement, bool) {
+	outerLoopLabels, outerInFunction, outerHasYield := p.loopLabels, p.inFunction, p.hasYield
+	p.loopLabels, p.inFunction, p.hasYield = nil, true, false
+	defer func() { p.loopLabels, p.inFunction, p.hasYield = outerLoopLabels, outerInFunction, outerHasYield }()
+
+	body := p.parseBlockStatement()
+
+	return body, p.hasYield
+}
+
 func (p *Parser) parseFunctionParameters() []*ast.Identifier {
 	identifiers := []*ast.Identifier{}
 
@@ -121,6 +155,26 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	return stmt
 }
 
+func (p *Parser) parseYieldStatement() *ast.YieldStatement {
+	stmt := &ast.YieldStatement{Token: p.curToken}
+
+	if !p.inFunction {
+		p.errors = append(p.errors, fmt.Sprintf("yield is not in a function at %s", stmt.Token.Pos))
+	}
+	p.hasYield = true
+
+	if !p.peekTokenIs(token.SEMICOLON) && !p.peekTokenIs(token.RBRACE) && !p.peekTokenIs(token.EOF) {
+		p.nextToken()
+		stmt.Value = p.parseExpression(LOWEST)
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
 func (p *Parser) parseIfStatement() *ast.IfStatement {
 	stmt := &ast.IfStatement{Token: p.curToken}
 
@@ -145,6 +199,19 @@ func (p *Parser) parseIfStatement() *ast.IfStatement {
 	if p.peekTokenIs(token.ELSE) {
 		p.nextToken()
 
+		// else if is an alternative block which has only if statement
+		if p.peekTokenIs(token.IF) {
+			p.nextToken()
+
+			elseIf := p.parseIfStatement()
+			if elseIf == nil {
+				return nil
+			}
+			stmt.Alternative = &ast.BlockStatement{Token: elseIf.Token, Statements: []ast.Statement{elseIf}}
+
+			return stmt
+		}
+
 		if !p.expectPeek(token.LBRACE) {
 			return nil
 		}
@@ -195,10 +262,55 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	return block
 }
 
-func (p *Parser) parseForStatement() *ast.ForStatement {
-	stmt := &ast.ForStatement{Token: p.curToken}
+// parseLabeledStatement parses `label: for ...` or `label: while ...`
+func (p *Parser) parseLabeledStatement() ast.Statement {
+	label := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	p.nextToken() // ':'
+
+	switch {
+	case p.peekTokenIs(token.FOR):
+		p.nextToken()
+		return p.parseForStatement(label)
+	case p.peekTokenIs(token.WHILE):
+		p.nextToken()
+		return p.parseWhileStatement(label)
+	default:
+		p.errors = append(p.errors, fmt.Sprintf("label %s must be followed by loop, got %s at %s", label.Value, p.peekToken.Type, p.peekToken.Pos))
+		return nil
+	}
+}
+
+func (p *Parser) parseForStatement(label *ast.Identifier) ast.Statement {
+	forToken := p.curToken
+
+	/*
+		This is:
+		for {
+			...
+		}
+	*/
+	if p.peekTokenIs(token.LBRACE) {
+		p.nextToken()
+
+		stmt := &ast.LoopStatement{Token: forToken, Label: label}
+		stmt.Body = p.parseLoopBody(label)
+
+		return stmt
+	}
 
 	p.nextToken()
+
+	if p.curTokenIs(token.IDENT) && (p.peekTokenIs(token.IN) || p.peekTokenIs(token.COMMA)) {
+		return p.parseForInStatement(forToken, label)
+	}
+
+	return p.parseLoopStatement(forToken, label)
+}
+
+func (p *Parser) parseForInStatement(forToken token.Token, label *ast.Identifier) ast.Statement {
+	stmt := &ast.ForStatement{Token: forToken, Label: label}
+
 	stmt.Value = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 
 	/*
@@ -211,7 +323,7 @@ func (p *Parser) parseForStatement() *ast.ForStatement {
 		p.nextToken()
 
 		if !p.peekTokenIs(token.IDENT) {
-			p.errors = append(p.errors, fmt.Sprintf("second argument to for-loop must be ident, got %v", p.peekToken))
+			p.errors = append(p.errors, fmt.Sprintf("second argument to for-loop must be ident, got %s at %s", p.peekToken.Type, p.peekToken.Pos))
 			return nil
 		}
 		p.nextToken()
@@ -230,8 +342,266 @@ func (p *Parser) parseForStatement() *ast.ForStatement {
 		return nil
 	}
 
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	stmt.Body = p.parseLoopBody(label)
+
+	return stmt
+}
+
+func (p *Parser) parseLoopStatement(forToken token.Token, label *ast.Identifier) ast.Statement {
+	stmt := &ast.LoopStatement{Token: forToken, Label: label}
+
+	/*
+		This is:
+		for CONDITION {
+			...
+		}
+		or
+		for INIT; CONDITION; POST {
+			...
+		}
+	*/
+
+	switch {
+	case p.curTokenIs(token.SEMICOLON): // no init statement
+	case p.curTokenIs(token.LET):
+		stmt.Init = p.parseLetStatement()
+		if !p.curTokenIs(token.SEMICOLON) && !p.expectPeek(token.SEMICOLON) {
+			return nil
+		}
+	default:
+		initToken := p.curToken
+		exp := p.parseExpression(LOWEST)
+
+		if p.peekTokenIs(token.LBRACE) {
+			p.nextToken()
+
+			stmt.Condition = exp
+			stmt.Body = p.parseLoopBody(label)
+
+			return stmt
+		}
+
+		if !p.expectPeek(token.SEMICOLON) {
+			return nil
+		}
+		stmt.Init = &ast.ExpressionStatement{Token: initToken, Expression: exp}
+	}
+
+	if !p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+		stmt.Condition = p.parseExpression(LOWEST)
+	}
+	if !p.expectPeek(token.SEMICOLON) {
+		return nil
+	}
+
+	if !p.peekTokenIs(token.LBRACE) {
+		p.nextToken()
+		stmt.Post = p.parseExpression(LOWEST)
+	}
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	stmt.Body = p.parseLoopBody(label)
+
+	return stmt
+}
+
+func (p *Parser) parseWhileStatement(label *ast.Identifier) ast.Statement {
+	stmt := &ast.LoopStatement{Token: p.curToken, Label: label}
+
 	p.nextToken()
+	stmt.Condition = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	stmt.Body = p.parseLoopBody(label)
+
+	return stmt
+}
+
+// parseLoopBody parses block statement, in which break and continue are allowed
+func (p *Parser) parseLoopBody(label *ast.Identifier) *ast.BlockStatement {
+	name := ""
+	if label != nil {
+		name = label.Value
+	}
+
+	p.loopLabels = append(p.loopLabels, name)
+	defer func() { p.loopLabels = p.loopLabels[:len(p.loopLabels)-1] }()
+
+	return p.parseBlockStatement()
+}
+
+func (p *Parser) parseBranchStatement() ast.Statement {
+	stmt := &ast.BranchStatement{Token: p.curToken}
+
+	if p.peekTokenIs(token.IDENT) {
+		p.nextToken()
+		stmt.Label = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	}
+
+	if !p.isInLoop(stmt.Label) {
+		if stmt.Label != nil {
+			p.errors = append(p.errors, fmt.Sprintf("%s label not defined: %s at %s", stmt.Token.Literal, stmt.Label.Value, stmt.Label.Pos()))
+		} else {
+			p.errors = append(p.errors, fmt.Sprintf("%s is not in a loop at %s", stmt.Token.Literal, stmt.Token.Pos))
+		}
+		return nil
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) isInLoop(label *ast.Identifier) bool {
+	if label == nil {
+		return len(p.loopLabels) > 0
+	}
+
+	for _, name := range p.loopLabels {
+		if name == label.Value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *Parser) parseTryStatement() ast.Statement {
+	stmt := &ast.TryStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
 	stmt.Body = p.parseBlockStatement()
 
+	/*
+		This is:
+		catch (e) {
+			...
+		}
+		or
+		catch {
+			...
+		}
+	*/
+	if p.peekTokenIs(token.CATCH) {
+		p.nextToken()
+
+		if p.peekTokenIs(token.LPAREN) {
+			p.nextToken()
+
+			if !p.expectPeek(token.IDENT) {
+				return nil
+			}
+			stmt.Param = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+			if !p.expectPeek(token.RPAREN) {
+				return nil
+			}
+		}
+
+		if !p.expectPeek(token.LBRACE) {
+			return nil
+		}
+		stmt.Catch = p.parseBlockStatement()
+	}
+
+	if p.peekTokenIs(token.FINALLY) {
+		p.nextToken()
+
+		if !p.expectPeek(token.LBRACE) {
+			return nil
+		}
+		stmt.Finally = p.parseBlockStatement()
+	}
+
+	if stmt.Catch == nil && stmt.Finally == nil {
+		p.errors = append(p.errors, fmt.Sprintf("try must be followed by catch or finally, got %s at %s", p.peekToken.Type, p.peekToken.Pos))
+		return nil
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseThrowStatement() ast.Statement {
+	stmt := &ast.ThrowStatement{Token: p.curToken}
+
+	p.nextToken()
+
+	stmt.Value = p.parseExpression(LOWEST)
+	if stmt.Value == nil {
+		return nil
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseImportStatement() ast.Statement {
+	stmt := &ast.ImportStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.STRING) {
+		return nil
+	}
+	stmt.Path = &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
+
+	if p.peekTokenIs(token.AS) {
+		p.nextToken()
+
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		stmt.Alias = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseFromImportStatement() ast.Statement {
+	stmt := &ast.ImportStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.STRING) {
+		return nil
+	}
+	stmt.Path = &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
+
+	if !p.expectPeek(token.IMPORT) {
+		return nil
+	}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	stmt.Names = append(stmt.Names, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		stmt.Names = append(stmt.Names, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
 	return stmt
 }
@@ -4,27 +4,39 @@ import (
 	"bufio"
 	"fmt"
 	"io"
-	"pythia/evaluator"
 	"pythia/lexer"
 	"pythia/object"
 	"pythia/parser"
+	"pythia/runner"
+	"strings"
 )
 
-const PROMPT = ">> "
+const (
+	PROMPT              = ">> "
+	CONTINUATION_PROMPT = ".. "
+)
+
+// Start reads and runs inputs with engine until EOF. All inputs share the global scope.
+func Start(in io.Reader, out io.Writer, engine string) {
+	e, err := runner.NewEngine(engine)
+	if err != nil {
+		io.WriteString(out, err.Error()+"\n")
+		return
+	}
 
-func Start(in io.Reader, out io.Writer) {
 	scanner := bufio.NewScanner(in)
-	env := object.NewEnvironment()
+	sources := map[string]string{} // functions defined in previous inputs can raise errors
 
-	for {
-		fmt.Fprintf(out, PROMPT)
-		scanned := scanner.Scan()
-		if !scanned {
+	for count := 1; ; count++ {
+		input, ok := readInput(scanner, out)
+		if !ok {
 			return
 		}
 
-		line := scanner.Text()
-		l := lexer.New(line)
+		filename := fmt.Sprintf("<stdin:%d>", count)
+		sources[filename] = input
+
+		l := lexer.NewFile(filename, input)
 		p := parser.New(l)
 
 		program := p.ParseProgram()
@@ -33,7 +45,16 @@ func Start(in io.Reader, out io.Writer) {
 			continue
 		}
 
-		evaluated := evaluator.Eval(program, env)
+		evaluated, err := e.Eval(program)
+		if err != nil {
+			io.WriteString(out, "\t"+err.Error()+"\n")
+			continue
+		}
+
+		if errObj, ok := evaluated.(*object.Error); ok {
+			runner.PrintError(out, errObj, sources)
+			continue
+		}
 
 		if evaluated != nil {
 			io.WriteString(out, evaluated.Inspect())
@@ -42,6 +63,93 @@ func Start(in io.Reader, out io.Writer) {
 	}
 }
 
+// readInput reads lines until brackets are balanced and strings are terminated.
+func readInput(scanner *bufio.Scanner, out io.Writer) (string, bool) {
+	var lines []string
+
+	fmt.Fprint(out, PROMPT)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+
+		input := strings.Join(lines, "\n")
+		if IsComplete(input) {
+			return input, true
+		}
+
+		fmt.Fprint(out, CONTINUATION_PROMPT)
+	}
+
+	if len(lines) > 0 { // EOF in the middle of input, let parser report it
+		return strings.Join(lines, "\n"), true
+	}
+
+	return "", false
+}
+
+// IsComplete reports whether input has no unclosed `{`, `(`, `[`, string and block comment.
+func IsComplete(input string) bool {
+	depth := 0
+	inString := false
+	inRawString := false
+	escaped := false
+	inLineComment := false
+	inBlockComment := false
+	blockCommentStart := 0
+
+	runes := []rune(input)
+	for i, ch := range runes {
+		next := rune(0)
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+
+		if inLineComment {
+			inLineComment = ch != '\n'
+			continue
+		}
+		if inBlockComment {
+			if ch == '/' && i > blockCommentStart+2 && runes[i-1] == '*' {
+				inBlockComment = false
+			}
+			continue
+		}
+		if inRawString {
+			inRawString = ch != '`'
+			continue
+		}
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case ch == '\\':
+				escaped = true
+			case ch == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch {
+		case ch == '/' && next == '/':
+			inLineComment = true
+		case ch == '/' && next == '*':
+			inBlockComment = true
+			blockCommentStart = i
+		case ch == '"':
+			inString = true
+		case ch == '`':
+			inRawString = true
+		case ch == '{' || ch == '(' || ch == '[':
+			depth++
+		case ch == '}' || ch == ')' || ch == ']':
+			depth--
+		}
+	}
+
+	// too many closing brackets can't be fixed by more input, so let parser report it
+	return !inString && !inRawString && !inBlockComment && depth <= 0
+}
+
 func printParseErrors(out io.Writer, errors []string) {
 	for _, msg := range errors {
 		io.WriteString(out, "\t"+msg+"\n")
@@ -0,0 +1,84 @@
+package runner
+
+import (
+	"fmt"
+	"pythia/ast"
+	"pythia/compiler"
+	"pythia/evaluator"
+	"pythia/object"
+	"pythia/vm"
+)
+
+const (
+	ENGINE_EVAL = "eval" // tree-walking evaluator
+	ENGINE_VM   = "vm"   // bytecode compiler and virtual machine
+)
+
+// Engine runs programs in the same global scope, e.g. inputs of REPL
+type Engine interface {
+	// Set defines a global variable before programs run
+	Set(name string, val object.Object)
+	// Eval returns the value of the last statement, or the uncaught error as *object.Error.
+	// Compile error and InternalError are returned as error.
+	Eval(program *ast.Program) (object.Object, error)
+}
+
+func NewEngine(name string) (Engine, error) {
+	switch name {
+	case ENGINE_EVAL:
+		return &evalEngine{env: object.NewEnvironment()}, nil
+	case ENGINE_VM:
+		return &vmEngine{compiler: compiler.New(), globals: vm.NewGlobals()}, nil
+	default:
+		return nil, fmt.Errorf("unknown engine: %s", name)
+	}
+}
+
+// InternalError is a panic while an engine runs a program. It is a bug of the interpreter, not of the program,
+// so it is reported instead of crashing the process and losing the state of REPL.
+type InternalError struct {
+	Value interface{}
+}
+
+func (e *InternalError) Error() string {
+	return fmt.Sprintf("internal error: %v", e.Value)
+}
+
+// recoverInternalError turns a panic into InternalError, it must be deferred in Eval
+func recoverInternalError(err *error) {
+	if r := recover(); r != nil {
+		*err = &InternalError{Value: r}
+	}
+}
+
+type evalEngine struct {
+	env *object.Environment
+}
+
+func (e *evalEngine) Set(name string, val object.Object) { e.env.Set(name, val) }
+
+func (e *evalEngine) Eval(program *ast.Program) (result object.Object, err error) {
+	defer recoverInternalError(&err)
+
+	return evaluator.Eval(program, e.env), nil
+}
+
+type vmEngine struct {
+	compiler *compiler.Compiler
+	globals  *vm.Globals
+}
+
+func (e *vmEngine) Set(name string, val object.Object) {
+	e.globals.Set(e.compiler.DefineGlobal(name), val)
+}
+
+func (e *vmEngine) Eval(program *ast.Program) (result object.Object, err error) {
+	defer recoverInternalError(&err)
+
+	bytecode, err := e.compiler.Compile(program)
+	if err != nil {
+		return nil, err
+	}
+
+	return vm.NewWithGlobals(bytecode, e.globals).Run(), nil
+}
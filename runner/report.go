@@ -0,0 +1,73 @@
+package runner
+
+import (
+	"io"
+	"io/ioutil"
+	"pythia/object"
+	"pythia/token"
+	"strings"
+)
+
+// PrintError writes runtime error like Python traceback, from the outermost call to where the error is raised.
+// Each position is followed by the source line with a caret if the source is known.
+// sources maps filename of position to the source text.
+func PrintError(out io.Writer, err *object.Error, sources map[string]string) {
+	frames := err.Traceback()
+
+	if len(frames) > 0 {
+		io.WriteString(out, "Traceback (most recent call last):\n")
+	}
+
+	for _, frame := range frames {
+		io.WriteString(out, "  "+frame.String()+"\n")
+
+		if source, ok := lookupSource(frame.Pos.Filename, sources); ok {
+			io.WriteString(out, Snippet(source, frame.Pos))
+		}
+	}
+
+	io.WriteString(out, err.Inspect()+"\n")
+}
+
+// lookupSource finds source in sources, or reads it from file, e.g. imported module
+func lookupSource(filename string, sources map[string]string) (string, bool) {
+	if source, ok := sources[filename]; ok {
+		return source, true
+	}
+
+	if filename == "" {
+		return "", false
+	}
+
+	source, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return "", false
+	}
+
+	return string(source), true
+}
+
+// Snippet returns the source line of position, and a caret under the column.
+func Snippet(source string, pos token.Position) string {
+	lines := strings.Split(source, "\n")
+	if pos.Line < 1 || pos.Line > len(lines) {
+		return ""
+	}
+
+	line := strings.TrimRight(lines[pos.Line-1], "\r")
+
+	// keep tabs in padding, so the caret is placed under the column regardless of tab width
+	var padding strings.Builder
+	for i, ch := range []rune(line) {
+		if i >= pos.Column-1 {
+			break
+		}
+		if ch == '\t' {
+			padding.WriteRune('\t')
+		} else {
+			padding.WriteRune(' ')
+		}
+	}
+
+	return "    " + line + "\n    " + padding.String() + "^\n"
+}
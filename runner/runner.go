@@ -0,0 +1,88 @@
+package runner
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"pythia/lexer"
+	"pythia/object"
+	"pythia/parser"
+	"strings"
+)
+
+const (
+	EXIT_SUCCESS = 0
+	EXIT_FAILURE = 1
+)
+
+// RunFile reads a script file and runs it with engine. argv[0] is the script path.
+func RunFile(path string, args []string, engine string, errOut io.Writer) int {
+	source, err := ioutil.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(errOut, "can't open file %q: %s\n", path, err)
+		return EXIT_FAILURE
+	}
+
+	e, err := NewEngine(engine)
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return EXIT_FAILURE
+	}
+	e.Set("argv", newArgv(path, args))
+
+	return run(path, string(source), e, errOut)
+}
+
+// Run evaluates a whole source with given environment, and returns exit code.
+func Run(source string, env *object.Environment, errOut io.Writer) int {
+	return run("", source, &evalEngine{env: env}, errOut)
+}
+
+func run(filename string, source string, engine Engine, errOut io.Writer) int {
+	l := lexer.NewFile(filename, stripShebang(source))
+	p := parser.New(l)
+
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		for _, msg := range p.Errors() {
+			io.WriteString(errOut, msg+"\n")
+		}
+		return EXIT_FAILURE
+	}
+
+	evaluated, err := engine.Eval(program)
+	if err != nil {
+		io.WriteString(errOut, err.Error()+"\n")
+		return EXIT_FAILURE
+	}
+	if errObj, ok := evaluated.(*object.Error); ok {
+		PrintError(errOut, errObj, map[string]string{filename: source})
+		return EXIT_FAILURE
+	}
+
+	return EXIT_SUCCESS
+}
+
+func newArgv(path string, args []string) *object.Array {
+	elements := make([]object.Object, 0, len(args)+1)
+	elements = append(elements, &object.String{Value: path})
+	for _, arg := range args {
+		elements = append(elements, &object.String{Value: arg})
+	}
+
+	return &object.Array{Elements: elements}
+}
+
+// stripShebang blanks out "#!" line, but keeps the line break for line counting
+func stripShebang(source string) string {
+	if !strings.HasPrefix(source, "#!") {
+		return source
+	}
+
+	idx := strings.IndexByte(source, '\n')
+	if idx < 0 {
+		return ""
+	}
+
+	return source[idx:]
+}
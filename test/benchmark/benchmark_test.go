@@ -0,0 +1,95 @@
+package benchmark
+
+import (
+	"pythia/lexer"
+	"pythia/object"
+	"pythia/parser"
+	"pythia/runner"
+	"testing"
+)
+
+var programs = map[string]string{
+	"Fib": `func fib(n) {
+  if (n < 2) { return n }
+  return fib(n - 1) + fib(n - 2)
+}
+fib(20)`,
+	"Loop": `let sum = 0
+for let i = 0; i < 100000; i += 1 {
+  if (i % 3 == 0) { sum += i }
+}
+sum`,
+	"Array": `let arr = []
+for i in range(0, 2000) { arr = append(arr, i * 2) }
+let total = 0
+for i, v in arr { total += arr[i] + v }
+total`,
+	"Closure": `func counter() {
+  let c = 0
+  return func() { c += 1; return c }
+}
+let next = counter()
+for let i = 0; i < 20000; i += 1 { next() }
+next()`,
+}
+
+func BenchmarkEval(b *testing.B) {
+	for name := range programs {
+		b.Run(name, func(b *testing.B) { benchmark(b, runner.ENGINE_EVAL, programs[name]) })
+	}
+}
+
+func BenchmarkVM(b *testing.B) {
+	for name := range programs {
+		b.Run(name, func(b *testing.B) { benchmark(b, runner.ENGINE_VM, programs[name]) })
+	}
+}
+
+// TestPrograms checks that benchmark programs give the same results with both engines
+func TestPrograms(t *testing.T) {
+	for name, source := range programs {
+		var results []string
+
+		for _, engine := range []string{runner.ENGINE_EVAL, runner.ENGINE_VM} {
+			result := eval(t, engine, source)
+			if result == nil {
+				t.Fatalf("%s returns nil with %s", name, engine)
+			}
+			if _, ok := result.(*object.Error); ok {
+				t.Fatalf("%s raises error with %s: %s", name, engine, result.Inspect())
+			}
+
+			results = append(results, result.Inspect())
+		}
+
+		if results[0] != results[1] {
+			t.Errorf("%s gives different results. eval=%s, vm=%s", name, results[0], results[1])
+		}
+	}
+}
+
+func benchmark(b *testing.B, engine string, source string) {
+	for i := 0; i < b.N; i++ {
+		eval(b, engine, source)
+	}
+}
+
+func eval(tb testing.TB, engine string, source string) object.Object {
+	p := parser.New(lexer.New(source))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		tb.Fatalf("parse errors: %v", p.Errors())
+	}
+
+	e, err := runner.NewEngine(engine)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	result, err := e.Eval(program)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return result
+}
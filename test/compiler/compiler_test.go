@@ -0,0 +1,280 @@
+package compiler
+
+import (
+	"pythia/ast"
+	"pythia/compiler"
+	"pythia/lexer"
+	"pythia/parser"
+	"strings"
+	"testing"
+)
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		op       compiler.Opcode
+		operands []int
+		expected []byte
+	}{
+		{compiler.OpConstant, []int{65534}, []byte{byte(compiler.OpConstant), 255, 254}},
+		{compiler.OpAdd, []int{}, []byte{byte(compiler.OpAdd)}},
+		{compiler.OpGetLocal, []int{1, 258}, []byte{byte(compiler.OpGetLocal), 1, 1, 2}},
+		{compiler.OpAssignLocal, []int{0, 3, 1}, []byte{byte(compiler.OpAssignLocal), 0, 0, 3, 1}},
+	}
+
+	for _, tt := range tests {
+		instruction := compiler.Make(tt.op, tt.operands...)
+
+		if len(instruction) != len(tt.expected) {
+			t.Errorf("instruction has wrong length. expected=%d, got=%d", len(tt.expected), len(instruction))
+			continue
+		}
+		for i, b := range tt.expected {
+			if instruction[i] != b {
+				t.Errorf("wrong byte at pos %d. expected=%d, got=%d", i, b, instruction[i])
+			}
+		}
+
+		def, err := compiler.Lookup(byte(tt.op))
+		if err != nil {
+			t.Fatalf("definition not found: %s", err)
+		}
+		operands, read := compiler.ReadOperands(def, instruction[1:])
+		if read != len(instruction)-1 {
+			t.Errorf("wrong number of bytes read. expected=%d, got=%d", len(instruction)-1, read)
+		}
+		for i, o := range tt.operands {
+			if operands[i] != o {
+				t.Errorf("wrong operand %d. expected=%d, got=%d", i, o, operands[i])
+			}
+		}
+	}
+}
+
+func TestInstructionsString(t *testing.T) {
+	instructions := concat(
+		compiler.Make(compiler.OpConstant, 1),
+		compiler.Make(compiler.OpGetLocal, 0, 2),
+		compiler.Make(compiler.OpAdd),
+		compiler.Make(compiler.OpIterNext, 12, 1),
+	)
+
+	expected := `0000 OpConstant 1
+0003 OpGetLocal 0 2
+0007 OpAdd
+0008 OpIterNext 12 1
+`
+	if instructions.String() != expected {
+		t.Errorf("instructions wrongly formatted.\nexpected=%q\ngot=%q", expected, instructions.String())
+	}
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []compiler.Instructions
+	}{
+		{
+			"1 + 2",
+			[]compiler.Instructions{
+				compiler.Make(compiler.OpConstant, 0),
+				compiler.Make(compiler.OpConstant, 1),
+				compiler.Make(compiler.OpAdd),
+				compiler.Make(compiler.OpPop),
+			},
+		},
+		{
+			"let a = 1; a",
+			[]compiler.Instructions{
+				compiler.Make(compiler.OpConstant, 0),
+				compiler.Make(compiler.OpSetGlobal, 0),
+				compiler.Make(compiler.OpNil),
+				compiler.Make(compiler.OpPop),
+				compiler.Make(compiler.OpGetGlobal, 0),
+				compiler.Make(compiler.OpPop),
+			},
+		},
+		{
+			"true && x",
+			[]compiler.Instructions{
+				compiler.Make(compiler.OpTrue),
+				compiler.Make(compiler.OpJumpIfFalseOrPop, 7),
+				compiler.Make(compiler.OpGetGlobal, 0),
+				compiler.Make(compiler.OpPop),
+			},
+		},
+		{
+			"if (true) { let b = 1 }",
+			[]compiler.Instructions{
+				compiler.Make(compiler.OpTrue),
+				compiler.Make(compiler.OpJumpIfFalse, 15),
+				compiler.Make(compiler.OpPushScope, 1),
+				compiler.Make(compiler.OpConstant, 0),
+				compiler.Make(compiler.OpSetLocal, 0, 0),
+				compiler.Make(compiler.OpPopScope),
+				compiler.Make(compiler.OpNil),
+				compiler.Make(compiler.OpPop),
+			},
+		},
+		{
+			"let a = 0; for a < 3 { a += 1 }",
+			[]compiler.Instructions{
+				compiler.Make(compiler.OpConstant, 0),
+				compiler.Make(compiler.OpSetGlobal, 0),
+				compiler.Make(compiler.OpNil),
+				compiler.Make(compiler.OpPop),
+				// the loop has no scope because it doesn't declare variables
+				compiler.Make(compiler.OpGetGlobal, 0),
+				compiler.Make(compiler.OpConstant, 1),
+				compiler.Make(compiler.OpLess),
+				compiler.Make(compiler.OpJumpIfFalse, 28),
+				compiler.Make(compiler.OpConstant, 2),
+				compiler.Make(compiler.OpAssignGlobal, 0, 1),
+				compiler.Make(compiler.OpJump, 8),
+				compiler.Make(compiler.OpNil),
+				compiler.Make(compiler.OpPop),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		bytecode, err := compiler.New().Compile(parse(t, tt.input))
+		if err != nil {
+			t.Fatalf("compile error for %q: %s", tt.input, err)
+		}
+
+		expected := concat(tt.expected...)
+		if bytecode.Main.Instructions.String() != expected.String() {
+			t.Errorf("wrong instructions for %q.\nexpected=\n%s\ngot=\n%s", tt.input, expected, bytecode.Main.Instructions)
+		}
+	}
+}
+
+func TestCompileFunction(t *testing.T) {
+	bytecode, err := compiler.New().Compile(parse(t, "func add(a, b) { let c = a + b; return c }"))
+	if err != nil {
+		t.Fatalf("compile error: %s", err)
+	}
+
+	fn, ok := bytecode.Constants[0].(*compiler.CompiledFunction)
+	if !ok {
+		t.Fatalf("constant is not function. got=%T", bytecode.Constants[0])
+	}
+
+	if fn.Name != "add" || fn.NumParameters != 2 || fn.NumLocals != 3 {
+		t.Errorf("wrong function. name=%s, parameters=%d, locals=%d", fn.Name, fn.NumParameters, fn.NumLocals)
+	}
+
+	expected := concat(
+		compiler.Make(compiler.OpGetLocal, 0, 0),
+		compiler.Make(compiler.OpGetLocal, 0, 1),
+		compiler.Make(compiler.OpAdd),
+		compiler.Make(compiler.OpSetLocal, 0, 2),
+		compiler.Make(compiler.OpGetLocal, 0, 2),
+		compiler.Make(compiler.OpReturn),
+		compiler.Make(compiler.OpNil),
+		compiler.Make(compiler.OpReturn),
+	)
+	if fn.Instructions.String() != expected.String() {
+		t.Errorf("wrong instructions.\nexpected=\n%s\ngot=\n%s", expected, fn.Instructions)
+	}
+
+	if fn.Inspect() != "func add(a, b) {\nlet c = (a + b);return c;\n}" {
+		t.Errorf("wrong inspect. got=%q", fn.Inspect())
+	}
+}
+
+func TestCompileTooManyInstructions(t *testing.T) {
+	body := strings.Repeat("x += 1\n", 10000)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x = 0; if (true) {\n" + body + "}", "too many instructions"},
+		{"let x = 0; func f() {\n" + body + "}", "compile error: too many instructions in function at line 1, column 21"},
+		{"let x = 0; for i in [1] {\n" + body + "}", "too many instructions"},
+	}
+
+	for _, tt := range tests {
+		_, err := compiler.New().Compile(parse(t, tt.input))
+		if err == nil {
+			t.Errorf("expected compile error for %q", tt.input[:30])
+			continue
+		}
+		if err.Error() != tt.expected {
+			t.Errorf("wrong error. expected=%q, got=%q", tt.expected, err)
+		}
+	}
+}
+
+func TestSymbolTable(t *testing.T) {
+	global := compiler.NewSymbolTable()
+	a := global.Define("a")
+
+	local := compiler.NewEnclosedSymbolTable(global)
+	b := local.Define("b")
+
+	block := compiler.NewEnclosedSymbolTable(local)
+	c := block.Define("c")
+
+	tests := []struct {
+		name          string
+		expected      compiler.Symbol
+		expectedDepth int
+	}{
+		{"a", a, 2},
+		{"b", b, 1},
+		{"c", c, 0},
+	}
+
+	for _, tt := range tests {
+		symbol, depth := block.Resolve(tt.name)
+		if symbol != tt.expected || depth != tt.expectedDepth {
+			t.Errorf("wrong symbol for %s. expected=%+v(%d), got=%+v(%d)", tt.name, tt.expected, tt.expectedDepth, symbol, depth)
+		}
+	}
+
+	if _, _, ok := block.ResolveDefined("len"); ok {
+		t.Errorf("undefined name is resolved")
+	}
+
+	// unknown name refers to global, which can be a builtin function
+	symbol, _ := block.Resolve("len")
+	if symbol.Scope != compiler.GLOBAL_SCOPE || symbol.Index != 1 {
+		t.Errorf("wrong symbol for unknown name. got=%+v", symbol)
+	}
+	if _, _, ok := block.ResolveDefined("len"); ok {
+		t.Errorf("referenced name is resolved as defined")
+	}
+
+	// reserved name resolves to the outer variable until it is defined
+	block.Reserve("a")
+	if symbol, depth := block.Resolve("a"); symbol != a || depth != 2 {
+		t.Errorf("reserved name is resolved before definition. got=%+v(%d)", symbol, depth)
+	}
+	shadow := block.Define("a")
+	if symbol, depth := block.Resolve("a"); symbol != shadow || depth != 0 || symbol.Index != 1 || block.NumDefinitions() != 2 {
+		t.Errorf("wrong symbol for defined name. got=%+v(%d)", symbol, depth)
+	}
+}
+
+func parse(t *testing.T, input string) *ast.Program {
+	t.Helper()
+
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parse errors for %q: %v", input, p.Errors())
+	}
+
+	return program
+}
+
+func concat(instructions ...compiler.Instructions) compiler.Instructions {
+	out := compiler.Instructions{}
+	for _, ins := range instructions {
+		out = append(out, ins...)
+	}
+
+	return out
+}
@@ -6,6 +6,7 @@ import (
 	"pythia/lexer"
 	"pythia/object"
 	"pythia/parser"
+	"pythia/token"
 	"testing"
 )
 
@@ -69,6 +70,10 @@ func TestEvalIntegerExpression(t *testing.T) {
 		{"10 ^ 20", 30},
 		{"100 >> 2", 25},
 		{"10 << 2", 40},
+		{"0xFF", 255},
+		{"0o755", 493},
+		{"0b1010 | 0b0101", 15},
+		{"1_000_000", 1000000},
 	}
 	for _, tt := range tests {
 		evaluated := testEval(tt.input)
@@ -76,6 +81,70 @@ func TestEvalIntegerExpression(t *testing.T) {
 	}
 }
 
+func TestEvalBigIntExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"9223372036854775807 + 1", "9223372036854775808"},
+		{"-9223372036854775807 - 2", "-9223372036854775809"},
+		{"9223372036854775807 * 2", "18446744073709551614"},
+		{"-(-9223372036854775807 - 1)", "9223372036854775808"},
+		{"(-9223372036854775807 - 1) / -1", "9223372036854775808"},
+		{"1 << 64", "18446744073709551616"},
+		{"(1 << 64) >> 63", "2"},
+		{"(1 << 64) - 1 & 0xFF", "255"},
+		{"(1 << 64) | 1", "18446744073709551617"},
+		{"(1 << 64) ^ (1 << 64)", "0"},
+		{"-(1 << 70) % 1000", "-424"},
+		{"123456789012345678901234567890 / 10", "12345678901234567890123456789"},
+		{"func fact(n) { if (n <= 1) { return 1 }\nreturn n * fact(n - 1) }\nfact(25)", "15511210043330985984000000"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. expected=%s, got=%v", tt.input, tt.expected, evaluated)
+		}
+	}
+}
+
+func TestBigIntDemotion(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"(9223372036854775807 + 1) - 1", 9223372036854775807},
+		{"(1 << 100) >> 98", 4},
+		{"(1 << 64) / (1 << 60)", 16},
+		{"let h = {}; h[1 << 70] = 5; h[1 << 70]", 5},
+		{"(1 << 64) > 1 ? 1 : 0", 1},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestBigIntComparison(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"(1 << 64) == (1 << 64)", true},
+		{"(1 << 64) != (1 << 65)", true},
+		{"(1 << 64) < (1 << 65)", true},
+		{"-(1 << 64) < 0", true},
+		{"(1 << 64) >= 5", true},
+		{"(1 << 64) == 18446744073709551616", true},
+		{"(1 << 64) > 1.5", true},
+	}
+
+	for _, tt := range tests {
+		testBooleanObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
 func TestEvalFloatExpression(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -94,6 +163,9 @@ func TestEvalFloatExpression(t *testing.T) {
 		{"7.2 % 5.2", 2.0},
 		{"50.0 / 2.0 * 2.0 + 10.0", 60.0},
 		{"2 * (5.2 + 10.8)", 32.0},
+		{"6.02e23", 6.02e23},
+		{"1_000.5", 1000.5},
+		{"2.5E-1", 0.25},
 	}
 	for _, tt := range tests {
 		evaluated := testEval(tt.input)
@@ -131,8 +203,7 @@ func TestEvalBooleanExpression(t *testing.T) {
 		{"true || false", true},
 		{"false || false", false},
 		{`"a" && false`, false},
-		{`"a" && "b"`, true},
-		{`"a" || false`, true},
+		{`null || false`, false},
 	}
 
 	for _, tt := range tests {
@@ -141,6 +212,45 @@ func TestEvalBooleanExpression(t *testing.T) {
 	}
 }
 
+func TestLogicalExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`"a" && "b"`, "b"},
+		{`"a" || false`, "a"},
+		{`null || "default"`, "default"},
+		{`let name = null || "default"; name`, "default"},
+		{`false && 1`, false},
+		{`1 && 2`, 2},
+		{`0 || 2`, 0},
+		{`let x = null; x != null && x.isEmpty()`, false},
+		{`let x = null; x == null || x.isEmpty()`, true},
+		{`let n = 0; func inc() { n += 1; return true }; false && inc(); true || inc(); n`, 0},
+		{`let n = 0; func inc() { n += 1; return true }; true && inc(); false || inc(); n`, 2},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			str, ok := evaluated.(*object.String)
+			if !ok {
+				t.Errorf("object is not String. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if str.Value != expected {
+				t.Errorf("String has wrong value. got=%q, want=%q", str.Value, expected)
+			}
+		}
+	}
+}
+
 func TestBangOperator(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -174,6 +284,9 @@ func TestIfElseExpression(t *testing.T) {
 		{"let a = 0; if (1 < 2) { a = 10 } else { a = 20 }; a;", 10},
 		{`func min(a,b) { if (a>b) { b } else { a } }; min(1,2)`, nil},
 		{`func max(a,b) { if (a>b) { return a } else { return b }}; max(1,2)`, 2},
+		{`func sign(n) { if (n > 0) { return 1 } else if (n < 0) { return -1 } else { return 0 } }; sign(-5)`, -1},
+		{`func sign(n) { if (n > 0) { return 1 } else if (n < 0) { return -1 } else { return 0 } }; sign(0)`, 0},
+		{`let a = 0; if (false) { a = 1 } else if (false) { a = 2 } else if (true) { a = 3 }; a;`, 3},
 	}
 
 	for _, tt := range tests {
@@ -190,6 +303,25 @@ func TestIfElseExpression(t *testing.T) {
 	}
 }
 
+func TestConditionalExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"true ? 1 : 2", 1},
+		{"null ? 1 : 2", 2},
+		{"let a = 1 < 2 ? 10 : 20; a;", 10},
+		{"func max(a, b) { return a > b ? a : b }; max(3, 7);", 7},
+		{"let n = 0; n > 0 ? 1 : n < 0 ? -1 : 0", 0},
+		{"let n = 0; true ? n : (n += 100); n;", 0},
+		{"let n = 0; false ? [][1] : n;", 0},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
 func TestReturnStatements(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -258,6 +390,70 @@ func TestErrorHandling(t *testing.T) {
 			"5; true + false; 5",
 			"unknown operator: BOOLEAN + BOOLEAN",
 		},
+		{
+			"(1 << 64) << -1",
+			"negative shift count: -1",
+		},
+		{
+			"1 << (1 << 64)",
+			"shift count too large: 18446744073709551616",
+		},
+		{
+			"1 / 0",
+			"division by zero",
+		},
+		{
+			"1 % 0",
+			"modulo by zero",
+		},
+		{
+			"(1 << 64) / 0",
+			"division by zero",
+		},
+		{
+			"(1 << 64) % 0",
+			"modulo by zero",
+		},
+		{
+			"1.5 / 0",
+			"division by zero",
+		},
+		{
+			"1 % 0.0",
+			"modulo by zero",
+		},
+		{
+			"let a = 1; a /= 0",
+			"division by zero",
+		},
+		{
+			"let a = [1]; a[0] %= 0",
+			"modulo by zero",
+		},
+		{
+			"1 << -1",
+			"negative shift count: -1",
+		},
+		{
+			"1 >> -1",
+			"negative shift count: -1",
+		},
+		{
+			"0 << -1",
+			"negative shift count: -1",
+		},
+		{
+			"[1, 2][1 << 64]",
+			"array index out of bound: 18446744073709551616",
+		},
+		{
+			"range(0, 1 << 64)",
+			"argument 18446744073709551616 is too large",
+		},
+		{
+			"for x in range(1) { x }",
+			"wrong number of arguments. got=1, want= 2 or 3",
+		},
 		{
 			"foobar",
 			"identifier not found: foobar",
@@ -275,8 +471,8 @@ func TestErrorHandling(t *testing.T) {
 			"array index out of bound: 3",
 		},
 		{
-			"[1, 2, 3][-1]",
-			"array index out of bound: -1",
+			"[1, 2, 3][-4]",
+			"array index out of bound: -4",
 		},
 		{
 			"{[1,2]: 3}",
@@ -314,6 +510,10 @@ func TestErrorHandling(t *testing.T) {
 			`let foobar = 1; foobar.call()`,
 			"INTEGER is not callable object",
 		},
+		{
+			`let n = 0; true ? n : n += 1`,
+			"cannot assign to (true ? n : n)",
+		},
 		{
 			`{}.foo()`,
 			"foo is unknown method, HASH",
@@ -340,6 +540,246 @@ func TestErrorHandling(t *testing.T) {
 
 }
 
+func TestErrorPosition(t *testing.T) {
+	tests := []struct {
+		input        string
+		expectedLine int
+		expectedCol  int
+	}{
+		{"5 + true;", 1, 3},
+		{"let a = 1;\nlet b = a - \"x\";", 2, 11},
+		{"foobar", 1, 1},
+		{"[1, 2][5]", 1, 7},
+		{"func f(x) {\n  return -x\n}\nf(true)", 2, 10},
+		{"len(1)", 1, 4},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+			continue
+		}
+
+		if errObj.Pos.Line != tt.expectedLine || errObj.Pos.Column != tt.expectedCol {
+			t.Errorf("wrong error position for %q. expected=%d:%d, got=%d:%d",
+				tt.input, tt.expectedLine, tt.expectedCol, errObj.Pos.Line, errObj.Pos.Column)
+		}
+	}
+}
+
+func TestErrorKind(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected object.ErrorKind
+	}{
+		{"5 + true;", object.TYPE_ERROR},
+		{"foobar", object.NAME_ERROR},
+		{"[1, 2, 3][3]", object.INDEX_ERROR},
+		{`let h = {"a": 1}; h["b"] += 1;`, object.KEY_ERROR},
+		{"[].bar()", object.ATTRIBUTE_ERROR},
+		{"range(1, 5, -1)", object.VALUE_ERROR},
+		{"range(1, true)", object.TYPE_ERROR},
+		{"1 / 0", object.ZERO_DIVISION_ERROR},
+		{"let a = 1.0; a %= 0", object.ZERO_DIVISION_ERROR},
+		{"1 << -1", object.VALUE_ERROR},
+		{"append(1, 2)", object.TYPE_ERROR},
+		{`throw "boom"`, object.ERROR},
+		{`throw error("ValueError", "bad")`, object.VALUE_ERROR},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned for %q. got=%T(%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+
+		if errObj.Kind != tt.expected {
+			t.Errorf("wrong error kind for %q. expected=%s, got=%s", tt.input, tt.expected, errObj.Kind)
+		}
+	}
+}
+
+func TestTryStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`let r = 0; try { r = 1 } catch (e) { r = 2 }; r;`, 1},
+		{`let r = 0; try { [1][5]; r = 1 } catch (e) { r = 2 }; r;`, 2},
+		{`let r = ""; try { [1][5] } catch (e) { r = e.kind() }; r;`, "IndexError"},
+		{`let r = ""; try { 1 + true } catch (e) { r = e.message() }; r;`, "type mismatch: INTEGER + BOOLEAN"},
+		{`let r = ""; try { throw "boom" } catch (e) { r = e.message() }; r;`, "boom"},
+		{`let r = ""; try { throw 42 } catch (e) { r = e.message() }; r;`, "42"},
+		{`let r = ""; try { throw error("KeyError", "no key") } catch (e) { r = e.kind() + ": " + e.message() }; r;`, "KeyError: no key"},
+		{`let r = 0; try { foo } catch { r = 3 }; r;`, 3},
+		{`let r = 0; try { r = 1 } finally { r += 10 }; r;`, 11},
+		{`let r = 0; try { throw "x" } catch (e) { r = 1 } finally { r += 10 }; r;`, 11},
+		{`let r = 0; try { try { throw "x" } finally { r = 1 } } catch (e) { r += 10 }; r;`, 11},
+		{`let r = ""; try { try { throw "inner" } catch (e) { throw e } } catch (e) { r = e.message() }; r;`, "inner"},
+		{`let r = ""; try { try { throw "a" } catch (e) { throw "b" } } catch (e) { r = e.message() }; r;`, "b"},
+		{`let n = 0; func f() { try { return 1 } finally { n = 5 } }; f() + n;`, 6},
+		{`func f() { try { return 1 } finally { return 2 } }; f();`, 2},
+		{`func f() { throw "in f" }; let r = ""; try { f() } catch (e) { r = e.message() }; r;`, "in f"},
+		{`let n = 0; for i in [1, 2, 3] { try { if (i == 2) { break } } finally { n += 1 } }; n;`, 2},
+		{`let e = error("x"); type(e) == type(e);`, true},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			str, ok := evaluated.(*object.String)
+			if !ok {
+				t.Errorf("object is not String for %q. got=%T (%+v)", tt.input, evaluated, evaluated)
+				continue
+			}
+			if str.Value != expected {
+				t.Errorf("wrong value for %q. expected=%q, got=%q", tt.input, expected, str.Value)
+			}
+		}
+	}
+}
+
+func TestUncaughtError(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{`try { throw "a" } catch (e) { throw "b" }`, "b"},
+		{`try { throw "a" } finally { 1 }`, "a"},
+		{`try { 1 } finally { throw "c" }`, "c"},
+		{`try { throw "a" } catch (e) { 1 } finally { throw "c" }`, "c"},
+		{`throw error("x") + 1`, "type mismatch: EXCEPTION + INTEGER"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned for %q. got=%T(%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expectedMessage, errObj.Message)
+		}
+	}
+}
+
+func TestErrorTrace(t *testing.T) {
+	input := `func inner() {
+  return [][0]
+}
+func outer() {
+  inner()
+}
+outer()`
+
+	evaluated := testEval(input)
+
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+	}
+
+	expected := []object.Frame{ // called functions and call sites, innermost first
+		{Function: "inner", Pos: token.Position{Line: 5, Column: 3}},
+		{Function: "outer", Pos: token.Position{Line: 7, Column: 1}},
+	}
+	if len(errObj.Stack) != len(expected) {
+		t.Fatalf("wrong stack length. expected=%d, got=%d", len(expected), len(errObj.Stack))
+	}
+	for i, frame := range expected {
+		got := errObj.Stack[i]
+		if got.Function != frame.Function || got.Pos.Line != frame.Pos.Line || got.Pos.Column != frame.Pos.Column {
+			t.Errorf("stack[%d] is wrong. expected=%+v, got=%+v", i, frame, got)
+		}
+	}
+
+	traceback := []string{"line 7, in <module>", "line 5, in outer", "line 2, in inner"}
+	for i, frame := range errObj.Traceback() {
+		if frame.String() != traceback[i] {
+			t.Errorf("traceback[%d] is wrong. expected=%q, got=%q", i, traceback[i], frame.String())
+		}
+	}
+}
+
+func TestExceptionTrace(t *testing.T) {
+	input := `let f = func() { throw "x" }
+let t = []
+try { f() } catch (e) { t = e.trace() }
+t`
+
+	evaluated := testEval(input)
+
+	arr, ok := evaluated.(*object.Array)
+	if !ok {
+		t.Fatalf("object is not Array. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	expected := "[line 3, in <module>, line 1, in <anonymous>]"
+	if arr.Inspect() != expected {
+		t.Errorf("wrong trace. expected=%q, got=%q", expected, arr.Inspect())
+	}
+}
+
+func TestRecursionLimit(t *testing.T) {
+	evaluated := testEval("func f() { return f() }\nf()")
+
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+	}
+	if errObj.Message != "maximum recursion depth exceeded" {
+		t.Errorf("wrong error message. got=%q", errObj.Message)
+	}
+	if len(errObj.Stack) != evaluator.MAX_CALL_DEPTH-1 {
+		t.Errorf("wrong error stack length. expected=%d, got=%d", evaluator.MAX_CALL_DEPTH-1, len(errObj.Stack))
+	}
+
+	// the depth is restored after the error, so deep calls run again
+	input := `func f() { return f() }
+let caught = false
+try { f() } catch (e) { caught = true }
+func down(n) { if (n == 0) { return 0 }; return down(n - 1) + 1 }
+caught ? down(1000) : -1`
+	testIntegerObject(t, testEval(input), 1000)
+}
+
+func TestRethrownExceptionTrace(t *testing.T) {
+	input := `let e = error("ValueError", "boom")
+let traces = []
+func f() { throw e }
+try { f() } catch (x) { traces = append(traces, x.trace()) }
+try { f() } catch (x) { traces = append(traces, x.trace()) }
+try { throw e } catch (x) { traces = append(traces, x.trace()) }
+traces`
+
+	evaluated := testEval(input)
+
+	arr, ok := evaluated.(*object.Array)
+	if !ok {
+		t.Fatalf("object is not Array. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	expected := "[[line 4, in <module>, line 3, in f], [line 5, in <module>, line 3, in f], [line 6, in <module>]]"
+	if arr.Inspect() != expected {
+		t.Errorf("wrong trace. expected=%q, got=%q", expected, arr.Inspect())
+	}
+}
+
 func TestLetStatements(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -375,6 +815,9 @@ func TestAssignmentExpressions(t *testing.T) {
 		{`let arr = [0,1,2]; arr[0] = 3; arr[0];`, 3},
 		{`let h = {"a": 1}; if(1<2) { h["a"] += 2 }; h["a"];`, 3},
 		{`let h = {"a": 1}; h["b"] = 2; h["b"];`, 2},
+		{`let a = [[1, 2], [3]]; a[0][1] = 5; a[0][1];`, 5},
+		{`let h = {"a": [1]}; h["a"][0] += 2; h["a"][0];`, 3},
+		{`let a = [{"k": 1}]; a[0]["k"] *= 4; a[0]["k"];`, 4},
 	}
 
 	for _, tt := range tests {
@@ -441,6 +884,31 @@ func TestFunctionApplication(t *testing.T) {
 	}
 }
 
+func TestClosures(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let double = func(x) { return x * 2 }; double(4);", 8},
+		{"func apply(f, x) { return f(x) }; apply(func(x) { return x + 1 }, 1);", 2},
+		{"func() { return 3 }();", 3},
+		{"func adder(n) { return func(x) { return x + n } }; let add2 = adder(2); let add3 = adder(3); add2(1) + add3(1);", 7},
+		{"func counter() { let c = 0; return func() { c += 1; return c } }; let next = counter(); next(); next(); next();", 3},
+		{"func counter() { let c = 0; return func() { if (true) { c += 1 }; return c } }; let next = counter(); next(); next();", 2},
+		{"func counter() { let c = 0; return func() { c += 1; return c } }; let a = counter(); let b = counter(); a(); a(); b();", 1},
+		{"let c = 10; func counter() { let c = 0; return func() { c += 1; return c } }; let a = counter(); let b = counter(); a(); a(); b();", 1},
+		{"let c = 10; func counter() { let c = 0; return func() { c += 1; return c } }; let a = counter(); let b = counter(); a(); b(); a() * 100 + c;", 210},
+		{"let x = 1; func f() { let y = x; let x = 2; return y * 10 + x }; f() * 10 + x;", 121},
+		{"let x = 1; if (true) { let x = 2 }; func g() { func x() { return 5 }; return x() }; g() * 10 + x;", 51},
+		{`let h = {"inc": func(x) { return x + 1 }}; h["inc"](1);`, 2},
+		{"let fact = func(n) { if (n < 2) { return 1 }; return n * fact(n - 1) }; fact(5);", 120},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
 func TestStringLiteral(t *testing.T) {
 	input := `"Hello World!"`
 
@@ -477,6 +945,11 @@ func TestBuiltinFunctions(t *testing.T) {
 		{`len("")`, 0},
 		{`len("four")`, 4},
 		{`len("hello world")`, 11},
+		{`len("a\nb")`, 3},
+		{`len("한글")`, 2},
+		{`len("café 😀")`, 6},
+		{`len("\"q\"")`, 3},
+		{"len(`a\\nb`)", 4},
 		{`len(1)`, "argument to len not supported, got INTEGER"},
 		{`len("one", "two")`, "wrong number of arguments. got=2, want=1"},
 		{`len([1, 2, 3])`, 3},
@@ -486,10 +959,18 @@ func TestBuiltinFunctions(t *testing.T) {
 		{"append([1], 2)", []int{1, 2}},
 		{"append([1], 2, 3)", "wrong number of arguments. got=3, want=2"},
 		{"append(1, 2)", "argument to append must be ARRAY, got INTEGER"},
-		{"range(1,4)", []int{1, 2, 3}},
-		{"range(1,4,2)", []int{1, 3}},
-		{"range(5,1,-1)", []int{5, 4, 3, 2}},
-		{"range(-1,-5,-2)", []int{-1, -3}},
+		{"array(range(1,4))", []int{1, 2, 3}},
+		{"array(range(1,4,2))", []int{1, 3}},
+		{"array(range(5,1,-1))", []int{5, 4, 3, 2}},
+		{"array(range(-1,-5,-2))", []int{-1, -3}},
+		{"append(range(0, 2), 5)", []int{0, 1, 5}},
+		{"array([1, 2])", []int{1, 2}},
+		{"array(1)", "argument to array must be iterable, got INTEGER"},
+		{`array("한a")`, []string{"한", "a"}},
+		{`bytes("aé")`, []int{97, 195, 169}},
+		{`runes("aé한")`, []int{97, 233, 54620}},
+		{`bytes(1)`, "argument to bytes must be STRING, got INTEGER"},
+		{`runes()`, "wrong number of arguments. got=0, want=1"},
 		{`let h = {"a": 1}; delete(h, "a"); h`, map[object.HashKey]object.HashPair{}},
 		{`let h = {"a": 1, "b": 2}; delete(h, "a", "b"); h`, "wrong number of arguments. got=3, want=2"},
 		{`[].isEmpty()`, true},
@@ -543,9 +1024,15 @@ func TestBuiltinFunctions(t *testing.T) {
 				continue
 			}
 
-			for k, pair := range hash.Pairs {
-				if expected[k].Value != pair.Value {
-					t.Errorf("object has wrong value. got=%+v, want=%+v", pair.Value, expected[k].Value)
+			if hash.Len() != len(expected) {
+				t.Errorf("hash has wrong num of pairs. want=%d, got=%d", len(expected), hash.Len())
+				continue
+			}
+
+			for k, expectedPair := range expected {
+				pair, ok := hash.Get(k)
+				if !ok || !expectedPair.Value.Equals(pair.Value) {
+					t.Errorf("object has wrong value. got=%+v, want=%+v", pair.Value, expectedPair.Value)
 				}
 			}
 		case []bool:
@@ -622,6 +1109,257 @@ func TestBuiltinFunctions(t *testing.T) {
 	}
 }
 
+func TestStringMethods(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"a,b,,c".split(",")`, "[a, b, , c]"},
+		{`" a  b\n".split()`, "[a, b]"},
+		{`"abc".split("x")`, "[abc]"},
+		{`", ".join(["a", "b", "c"])`, "a, b, c"},
+		{`"".join("abc")`, "abc"},
+		{`"-".join([])`, ""},
+		{`"  hi \n".trim()`, "hi"},
+		{`"xxhixy".trim("xy")`, "hi"},
+		{`"  hi ".trimLeft()`, "hi "},
+		{`"  hi ".trimRight()`, "  hi"},
+		{`"--hi--".trimLeft("-")`, "hi--"},
+		{`"Héllo".upper()`, "HÉLLO"},
+		{`"HÉLLO".lower()`, "héllo"},
+		{`"aaa".replace("a", "b")`, "bbb"},
+		{`"aaa".replace("a", "b", 2)`, "bba"},
+		{`"한글abc".find("a")`, "2"},
+		{`"abcabc".indexOf("c")`, "2"},
+		{`"abc".find("z")`, "-1"},
+		{`"abc".startsWith("ab")`, "true"},
+		{`"abc".endsWith("ab")`, "false"},
+		{`"abc".contains("b")`, "true"},
+		{`"ab".repeat(3)`, "ababab"},
+		{`"ab".repeat(0)`, ""},
+		{`"7".padLeft(3, "0")`, "007"},
+		{`"한".padRight(3, "*")`, "한**"},
+		{`"abcd".padLeft(2)`, "abcd"},
+		{`"x".padRight(3) + "|"`, "x  |"},
+		{`"a\nb\r\nc\n".lines()`, "[a, b, c]"},
+		{`"".lines()`, "[]"},
+		{`"0123".isDigit()`, "true"},
+		{`"12a".isDigit()`, "false"},
+		{`"한글abc".isAlpha()`, "true"},
+		{`"".isAlpha()`, "false"},
+		{`"".isEmpty()`, "true"},
+		{`"{} + {} = {}".format(1, 2, 1 + 2)`, "1 + 2 = 3"},
+		{`"{1}{0}{1}".format("a", "b")`, "bab"},
+		{`"{name} is {age}".format({"name": "kim", "age": 3})`, "kim is 3"},
+		{`"{{{}}}".format([1])`, "{[1]}"},
+		{`let s = "a-b"; s.split("-")[1]`, "b"},
+		{`"abc".split("")`, "empty separator"},
+		{`"abc".split(1)`, "argument to split must be STRING, got INTEGER"},
+		{`"abc".split(",", 1)`, "wrong number of arguments. got=2, want=0 or 1"},
+		{`",".join([1])`, "sequence item 0: expected STRING, got INTEGER"},
+		{`",".join(1)`, "argument to join must be iterable, got INTEGER"},
+		{`"abc".upper(1)`, "wrong number of arguments. got=1, want=0"},
+		{`"ab".repeat(-1)`, "negative repeat count: -1"},
+		{`"ab".repeat(1 << 62)`, "repeated string is too long"},
+		{`"ab".padLeft(5, "xy")`, `fill character must be exactly one character, got "xy"`},
+		{`"ab".padLeft("5")`, "argument to padLeft must be INTEGER, got STRING"},
+		{`"{".format()`, "single '{' encountered in format string"},
+		{`"}".format()`, "single '}' encountered in format string"},
+		{`"{} {}".format(1)`, "format index 1 out of range"},
+		{`"{} {0}".format(1)`, "cannot switch from automatic field numbering to manual field specification"},
+		{`"{0} {}".format(1)`, "cannot switch from manual field specification to automatic field numbering"},
+		{`"{x}".format({})`, "x is not exist in format arguments"},
+		{`"abc".reverse()`, "reverse is unknown method, STRING"},
+		// boolean results are the same objects as literals
+		{`"abc".startsWith("x") ? 1 : 2`, "2"},
+		{`[1].isEmpty() || {1: 2}.isEmpty() || range(0, 1).isEmpty() ? 1 : 2`, "2"},
+		{`"abc".contains("b") == true`, "true"},
+		{`"a" == "b" ? 1 : 2`, "2"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil {
+			t.Errorf("no result for %q", tt.input)
+			continue
+		}
+
+		var got string
+		switch obj := evaluated.(type) {
+		case *object.Error:
+			got = obj.Message
+		default:
+			got = evaluated.Inspect()
+		}
+		if got != tt.expected {
+			t.Errorf("wrong result for %q. expected=%s, got=%s", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestArrayMethods(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`let a = [1, 2]; a.push(3, 4); a`, "[1, 2, 3, 4]"},
+		{`let a = [1, 2, 3]; [a.pop(), a]`, "[3, [1, 2]]"},
+		{`let a = [1, 2, 3]; [a.pop(0), a.pop(-1), a]`, "[1, 3, [2]]"},
+		{`let a = [1, 3]; a.insert(1, 2); a.insert(-10, 0); a.insert(10, 4); a`, "[0, 1, 2, 3, 4]"},
+		{`let a = [1, 2, 1]; a.remove(1); a`, "[2, 1]"},
+		{`let a = [1, 2]; a.clear(); a`, "[]"},
+		{`[1].push(2)`, "null"},
+		{`let a = [1]; [a.push(2), a.insert(0, 0), a.remove(0), a.forEach(func(x) {}), a.clear()]`, "[null, null, null, null, null]"},
+		{`let a = [1, 2]; print(a.push(3), a.insert(0, 0), a.remove(0), a.forEach(func(x) {}), a.clear()); a`, "[]"},
+		{`[1, "a", [2]].indexOf([2])`, "2"},
+		{`[1, 2].indexOf(3)`, "-1"},
+		{`[1, 2].contains(2)`, "true"},
+		{`[1, 2].contains("2")`, "false"},
+		{`let a = [1, 2, 3]; a.reverse(); a`, "[3, 2, 1]"},
+		{`[3, 1.5, 2, 18446744073709551616].sort()`, "[1.500000, 2, 3, 18446744073709551616]"},
+		{`["b", "c", "a"].sort()`, "[a, b, c]"},
+		{`[1, 3, 2].sort(func(a, b) { return b - a })`, "[3, 2, 1]"},
+		{`["bb", "a", "cc", "d"].sort(func(a, b) { return len(a) - len(b) })`, "[a, d, bb, cc]"},
+		{`let a = [2, 1]; a.sort(); a`, "[1, 2]"},
+		{`[1, "a", null].join()`, "1,a,null"},
+		{`["a", "b"].join(" - ")`, "a - b"},
+		{`[1, 2, 3, 4].slice(1, 3)`, "[2, 3]"},
+		{`[1, 2, 3, 4].slice(-2)`, "[3, 4]"},
+		{`[1, 2, 3, 4].slice(3, 1)`, "[]"},
+		{`[1, 2].first()`, "1"},
+		{`[].first()`, "null"},
+		{`[].last()`, "null"},
+		{`let a = [1, 2]; a.forEach(func(x) { a.push(x * 10) }); a`, "[1, 2, 10, 20]"},
+		{`let a = [1, 2, 3]; a.map(func(x) { a.clear(); return x })`, "[1, 2, 3]"},
+		{`let a = [1, 2]; [a.filter(func(x) { a.push(x); return true }), len(a)]`, "[[1, 2], 4]"},
+		{`let a = [1, 2, 3]; a.reduce(func(acc, x) { a.pop(); return acc + x })`, "6"},
+		{`let a = [1, 2]; [a.find(func(x) { a.insert(0, 0); return x == 2 }), a]`, "[2, [0, 0, 1, 2]]"},
+		{`let a = [1, 2]; a.all(func(x) { a.push(x); return true })`, "true"},
+		{`[1, 2, 3].map(func(x) { return x * 2 })`, "[2, 4, 6]"},
+		{`[1, 2].map(string)`, "[1, 2]"},
+		{`let double = func(x) { return x * 2 }; [1].map(double)`, "[2]"},
+		{`let n = 10; [1, 2].map(func(x) { return x + n })`, "[11, 12]"},
+		{`[1, 2, 3, 4].filter(func(x) { return x % 2 == 0 })`, "[2, 4]"},
+		{`[1, 2, 3, 4].reduce(func(acc, x) { return acc + x })`, "10"},
+		{`[1, 2, 3].reduce(func(acc, x) { return acc + string(x) }, "")`, "123"},
+		{`[].reduce(func(acc, x) { return acc + x }, 0)`, "0"},
+		{`[1, 2, 3].find(func(x) { return x > 1 })`, "2"},
+		{`[1, 2, 3].find(func(x) { return x > 3 })`, "null"},
+		{`[1, 2, 3].any(func(x) { return x > 2 })`, "true"},
+		{`[1, 2, 3].all(func(x) { return x > 2 })`, "false"},
+		{`[].all(func(x) { return false })`, "true"},
+		{`[0, null].any()`, "true"},
+		{`[true, false].all()`, "false"},
+		{`let sum = 0; [1, 2, 3].forEach(func(x) { sum += x }); sum`, "6"},
+		{`let a = [1, 2]; [1, 2].forEach(func(x) { a.push(x) }); a`, "[1, 2, 1, 2]"},
+		{`[1, 2, 3].filter(func(x) { return x > 1 }).map(func(x) { return x * 10 })`, "[20, 30]"},
+		{`[1, 2].map(func(x) { throw "bad " + string(x) })`, "bad 1"},
+		{`[1, 2].find(func(x) { return x.foo() })`, "INTEGER is not callable object"},
+		{`[1, 2].map(1)`, "not a function: INTEGER"},
+		{`[1, 2].map()`, "wrong number of arguments. got=0, want=1"},
+		{`[1, 2].map(func(a, b) { return a })`, "wrong number of arguments. got=1, want=2"},
+		{`[1, "a"].sort()`, "can't compare STRING and INTEGER"},
+		{`[1, 2].sort(func(a, b) { return "x" })`, "comparator must return number, got STRING"},
+		{`let a = [2, 1]; try { a.sort(func(a, b) { throw "x" }) } catch (e) {}; a`, "[2, 1]"},
+		{`[].pop()`, "pop from empty array"},
+		{`[1].pop(1)`, "pop index out of bound: 1"},
+		{`[1].remove(2)`, "2 is not in array"},
+		{`[1].insert("0", 1)`, "argument to insert must be INTEGER, got STRING"},
+		{`[].reduce(func(acc, x) { return acc + x })`, "reduce of empty array with no initial value"},
+		{`[1].push()`, "wrong number of arguments. got=0, want=1 or more"},
+		{`[1].join(1)`, "argument to join must be STRING, got INTEGER"},
+		// boolean results are the same objects as literals
+		{`[1].contains(2) ? 1 : 2`, "2"},
+		{`[1].any(func(x) { return x > 1 }) ? 1 : 2`, "2"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil {
+			t.Errorf("no result for %q", tt.input)
+			continue
+		}
+
+		var got string
+		switch obj := evaluated.(type) {
+		case *object.Error:
+			got = obj.Message
+		default:
+			got = evaluated.Inspect()
+		}
+		if got != tt.expected {
+			t.Errorf("wrong result for %q. expected=%s, got=%s", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestArrayMethodCallbackTrace(t *testing.T) {
+	input := `func check(x) {
+  return x.foo()
+}
+[1].map(check)`
+
+	errObj, ok := testEval(input).(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned")
+	}
+
+	traceback := []string{"line 4, in <module>", "line 2, in check"}
+	frames := errObj.Traceback()
+	if len(frames) != len(traceback) {
+		t.Fatalf("wrong traceback length. expected=%d, got=%d", len(traceback), len(frames))
+	}
+	for i, frame := range frames {
+		if frame.String() != traceback[i] {
+			t.Errorf("traceback[%d] is wrong. expected=%q, got=%q", i, traceback[i], frame.String())
+		}
+	}
+}
+
+func TestBuiltinInvoker(t *testing.T) {
+	// host-side builtin which calls its first argument with the rest
+	apply := &object.Builtin{Fn: func(invoker object.Invoker, args ...object.Object) object.Object {
+		return invoker.Invoke(args[0], args[1:]...)
+	}}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"apply(func(x, y) { return x + y }, 1, 2)", "3"},
+		{"let n = 10; apply(func() { n += 1 }); n", "11"},
+		{"apply(len, [1, 2])", "2"},
+		{"apply(apply, string, 1)", "1"},
+		{"apply(func(f) { return [1, 2].map(f) }, func(x) { return x * 3 })", "[3, 6]"},
+		{"apply(func() { throw \"boom\" })", "boom"},
+		{"apply(1)", "not a function: INTEGER"},
+		{"let r = 0; try { apply(func() { throw 5 }) } catch (e) { r = e.message() }; r", "5"},
+	}
+
+	for _, tt := range tests {
+		program := parser.New(lexer.New(tt.input)).ParseProgram()
+		env := object.NewEnvironment()
+		env.Set("apply", apply)
+
+		evaluated := evaluator.Eval(program, env)
+		if evaluated == nil {
+			t.Errorf("no result for %q", tt.input)
+			continue
+		}
+
+		var got string
+		switch obj := evaluated.(type) {
+		case *object.Error:
+			got = obj.Message
+		default:
+			got = evaluated.Inspect()
+		}
+		if got != tt.expected {
+			t.Errorf("wrong result for %q. expected=%s, got=%s", tt.input, tt.expected, got)
+		}
+	}
+}
+
 func TestBuiltinTypeFunction(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -676,6 +1414,79 @@ func TestBuiltinStringFunction(t *testing.T) {
 	}
 }
 
+func TestRange(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"range(1, 4)", "range(1, 4)"},
+		{"range(10, 0, -3)", "range(10, 0, -3)"},
+		{"type(range(0, 1))", "Type: RANGE"},
+		{"len(range(0, 10, 3))", "4"},
+		{"len(range(10, 0, -3))", "4"},
+		{"len(range(3, 3))", "0"},
+		{"len(range(-9223372036854775808, 9223372036854775807))", "18446744073709551615"},
+		{"range(0, 10, 3)[3]", "9"},
+		{"range(10, 0, -3)[1]", "7"},
+		{"range(0, 100000000000)[99999999999]", "99999999999"},
+		{"range(0, 10, 3).last()", "9"},
+		{"range(0, 0).isEmpty()", "true"},
+		{"range(0, 10, 3).contains(6)", "true"},
+		{"range(0, 10, 3).contains(7)", "false"},
+		{"range(10, 0, -3).contains(1)", "true"},
+		{"range(10, 0, -3).contains(0)", "false"},
+		{`range(0, 10).contains("1")`, "false"},
+		{"range(0, 10, 3).contains(7) ? 1 : 2", "2"},
+		{"range(0, 0).isEmpty() && !range(0, 3).contains(5) ? 1 : 2", "1"},
+		{"range(0, 3, 2) == range(0, 4, 2)", "true"},
+		{"range(0, 3) == range(0, 4)", "false"},
+		{"range(0, 0) == range(5, 5)", "true"},
+		{"let s = 0; for i in range(0, 100000000000) { if (i == 5) { break }\ns += i }\ns", "10"},
+		{"let s = \"\"; for i, v in range(5, 0, -2) { s += string(i) + string(v) }\ns", "051321"},
+		{"let r = range(0, 2); let s = 0; for a in r { for b in r { s += 1 } }\ns", "4"},
+		{"array(range(0, 3))", "[0, 1, 2]"},
+		{"array(range(0, 0))", "[]"},
+		{"range(0, 3)[3]", "range index out of bound: 3"},
+		{"range(0, 3)[-1]", "2"},
+		{"range(0, 3)[-4]", "range index out of bound: -4"},
+		{"range(-9223372036854775807 - 1, 9223372036854775807)[-1]", "9223372036854775806"},
+		{"let r = range(-9223372036854775807 - 1, 9223372036854775807); r[len(r) - 1]", "9223372036854775806"},
+		{"let r = range(-9223372036854775807 - 1, 9223372036854775807); r[len(r)]", "range index out of bound: 18446744073709551615"},
+		{"range(0, 3)[1 << 70]", "range index out of bound: 1180591620717411303424"},
+		{"array(range(0, 1 << 62))", "range(0, 4611686018427387904) is too long to convert to array"},
+		{"append(range(0, 1 << 62), 1)", "range(0, 4611686018427387904) is too long to convert to array"},
+		{"range(0, 1 << 62)[::2]", "slice of range(0, 4611686018427387904) is too long to convert to array"},
+		{"range(0, 0).last()", "null"},
+		{"func f() { }; range(f(), 3)", "argument to range must be INTEGER, got NULL"},
+		{`range(0, "3")`, "argument to range must be INTEGER, got STRING"},
+		{"func f() { }; append(f(), 1)", "argument to append must be ARRAY, got NULL"},
+		{"range(0, 3, 0)", "step of range can't be 0"},
+		{"range(0, 3).contains()", "wrong number of arguments. got=0, want=1"},
+		{"let r = range(0, 3); r[0] = 1", "r is unknown index type, RANGE"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil {
+			t.Errorf("no result for %q", tt.input)
+			continue
+		}
+
+		var got string
+		switch obj := evaluated.(type) {
+		case *object.String:
+			got = obj.Value
+		case *object.Error:
+			got = obj.Message
+		default:
+			got = evaluated.Inspect()
+		}
+		if got != tt.expected {
+			t.Errorf("wrong result for %q. expected=%s, got=%s", tt.input, tt.expected, got)
+		}
+	}
+}
+
 func TestArrayLiterals(t *testing.T) {
 	input := "[1, 2 * 2, 3 + 3]"
 
@@ -731,6 +1542,18 @@ func TestArrayIndexExpressions(t *testing.T) {
 			"let myArray = [1, 2, 3]; let i = myArray[0]; myArray[i]",
 			2,
 		},
+		{
+			"[1, 2, 3][-1]",
+			3,
+		},
+		{
+			"[1, 2, 3][-3]",
+			1,
+		},
+		{
+			"let myArray = [1, 2, 3]; myArray[-1] = 5; myArray[-2] += 3; myArray[1] + myArray[2]",
+			10,
+		},
 	}
 
 	for _, tt := range tests {
@@ -745,6 +1568,70 @@ func TestArrayIndexExpressions(t *testing.T) {
 
 }
 
+func TestSliceExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"hello"[1]`, "e"},
+		{`"hello"[-1]`, "o"},
+		{`"한글abc"[1]`, "글"},
+		{"[1, 2, 3, 4, 5][1:3]", "[2, 3]"},
+		{"[1, 2, 3, 4, 5][:2]", "[1, 2]"},
+		{"[1, 2, 3, 4, 5][3:]", "[4, 5]"},
+		{"[1, 2, 3, 4, 5][:]", "[1, 2, 3, 4, 5]"},
+		{"[1, 2, 3, 4, 5][::2]", "[1, 3, 5]"},
+		{"[1, 2, 3, 4, 5][::-1]", "[5, 4, 3, 2, 1]"},
+		{"[1, 2, 3, 4, 5][-2:]", "[4, 5]"},
+		{"[1, 2, 3, 4, 5][:-2]", "[1, 2, 3]"},
+		{"[1, 2, 3, 4, 5][4:1:-2]", "[5, 3]"},
+		{"[1, 2, 3, 4, 5][-100:100]", "[1, 2, 3, 4, 5]"},
+		{"[1, 2, 3, 4, 5][3:1]", "[]"},
+		{"[1, 2, 3, 4, 5][1 << 64:]", "[]"},
+		{"[1, 2, 3][null:null:null]", "[1, 2, 3]"},
+		{"let a = [1, 2, 3]; let b = a[:]; b[0] = 9; a", "[1, 2, 3]"},
+		{`"hello"[1:4]`, "ell"},
+		{`"hello"[::-1]`, "olleh"},
+		{`"한글abc"[:2]`, "한글"},
+		{"range(0, 100000000000, 10)[2:5]", "[20, 30, 40]"},
+		{"range(0, 10)[::-3]", "[9, 6, 3, 0]"},
+		{"let a = [1, 2, 3, 4]; a[1:3] = [7, 8, 9]; a", "[1, 7, 8, 9, 4]"},
+		{"let a = [1, 2, 3, 4]; a[1:3] = []; a", "[1, 4]"},
+		{"let a = [1, 2]; a[2:] = range(3, 5); a", "[1, 2, 3, 4]"},
+		{"let a = [1, 2]; a[:0] = \"ab\"; a", "[a, b, 1, 2]"},
+		{"let a = [1, 2, 3, 4]; a[::2] = [0, 0]; a", "[0, 2, 0, 4]"},
+		{"let a = [1, 2, 3]; a[::-1] = a; a", "[3, 2, 1]"},
+		{"[1, 2, 3][::0]", "slice step cannot be zero"},
+		{`[1, 2, 3]["a":]`, "slice indices must be integers or null, got STRING"},
+		{"1[1:]", "slice operator not supported: INTEGER"},
+		{`"abc"[3]`, "string index out of bound: 3"},
+		{"let a = [1, 2, 3, 4]; a[::2] = [0]; a", "attempt to assign sequence of size 1 to extended slice of size 2"},
+		{"let a = [1, 2]; a[:1] += [3]", "+= operation is not supported for slice"},
+		{"let a = [1, 2]; a[:1] = 3", "can only assign an iterable to slice, got INTEGER"},
+		{`let s = "ab"; s[:1] = "c"`, "s is unknown slice type, STRING"},
+		{`let a = [1]; a["x"] = 1`, "array index must be INTEGER, got STRING"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil {
+			t.Errorf("no result for %q", tt.input)
+			continue
+		}
+
+		var got string
+		switch obj := evaluated.(type) {
+		case *object.Error:
+			got = obj.Message
+		default:
+			got = evaluated.Inspect()
+		}
+		if got != tt.expected {
+			t.Errorf("wrong result for %q. expected=%s, got=%s", tt.input, tt.expected, got)
+		}
+	}
+}
+
 func TestHashLiterals(t *testing.T) {
 	input := `let two = "two";
 	{
@@ -771,12 +1658,12 @@ func TestHashLiterals(t *testing.T) {
 		evaluator.FALSE.HashKey():                  6,
 	}
 
-	if len(result.Pairs) != len(expected) {
-		t.Fatalf("Hash has wrong num of pairs. got=%d", len(result.Pairs))
+	if result.Len() != len(expected) {
+		t.Fatalf("Hash has wrong num of pairs. got=%d", result.Len())
 	}
 
 	for expectedKey, expectedValue := range expected {
-		pair, ok := result.Pairs[expectedKey]
+		pair, ok := result.Get(expectedKey)
 		if !ok {
 			t.Fatalf("no pair for given key in Pairs")
 		}
@@ -786,6 +1673,47 @@ func TestHashLiterals(t *testing.T) {
 
 }
 
+func TestHashOrder(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{"b": 1, "a": 2, 3: 3, true: 4}`, `{b: 1, a: 2, 3: 3, true: 4}`},
+		{`{"b": 1, "a": 2, "b": 3}`, `{b: 3, a: 2}`},
+		{`let h = {"b": 1, "a": 2}; h["c"] = 3; h["b"] = 4; h`, `{b: 4, a: 2, c: 3}`},
+		{`let h = {"b": 1, "a": 2}; delete(h, "b"); h["b"] = 5; h`, `{a: 2, b: 5}`},
+		{`{"z": 1, "y": 2, "x": 3}.keys()`, `[z, y, x]`},
+		{`{"z": 1, "y": 2, "x": 3}.values()`, `[1, 2, 3]`},
+		{`let h = {"z": 1, "y": 2, "x": 3}; let s = ""; for k, v in h { s += k + string(v) }
+s`, `z1y2x3`},
+		{`let h = {"a": 1, "b": 2, "c": 3}; let s = ""; for k in h { delete(h, "b"); s += k }
+s`, `ac`},
+		{`let h = {"a": 1}; let s = ""; for k in h { if (len(h) < 3) { h[k + "a"] = 1 }; s += k }
+s`, `aaaaaa`},
+		{`let h = {"a": 1, "b": 2}; let s = ""; for k in h { if (k == "a") { delete(h, "a"); delete(h, "b"); h["c"] = 3 }; s += k }
+s`, `ac`},
+		{`let h = {"a": 1, "b": 2, "c": 3}; let s = ""; for k in h { if (k == "a") { delete(h, "a"); delete(h, "c"); h["d"] = 4 }; s += k }
+s`, `abd`},
+		{`let h = {"a": 1, "b": 2, "c": 3}; let s = ""; for k in h { if (k == "b") { delete(h, "b"); delete(h, "c"); h["c"] = 5 }; s += k }
+s`, `abc`},
+		{`string({"b": [1], "a": {"c": null}})`, `{b: [1], a: {c: null}}`},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		var got string
+		if str, ok := evaluated.(*object.String); ok {
+			got = str.Value
+		} else {
+			got = evaluated.Inspect()
+		}
+		if got != tt.expected {
+			t.Errorf("wrong result for %q. expected=%s, got=%s", tt.input, tt.expected, got)
+		}
+	}
+}
+
 func TestHashIndexExpression(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -864,6 +1792,25 @@ func TestForLoopStatement(t *testing.T) {
 		{`let result = ""; for c in "abc" { result += c }; result;`, "abc"},
 		{`let result = ""; for i,c in "abc" { result += c }; result;`, "abc"},
 		{`let result = 0; for i,c in "abc" { result += i }; result;`, 3},
+		{`let result = ""; for i,c in "한글a" { result += string(i) + c }; result;`, "0한1글2a"},
+		{`let 합계 = 0; for 값 in [1, 2] { 합계 += 값 }; 합계;`, 3},
+		// loop variables don't overwrite outer variables of the same name
+		{`let i = 5; for i in [1, 2] {}; i;`, 5},
+		{`let v = 5; for i, v in [1, 2] {}; v;`, 5},
+		// nested and re-entrant loops over the same container have their own positions
+		{`let a = [1, 2, 3]; let result = 0; for x in a { for y in a { result += x * y } }; result;`, 36},
+		{`let s = "ab"; let result = ""; for x in s { for y in s { result += x + y } }; result;`, "aaabbabb"},
+		{`let h = {1: 1, 2: 2}; let result = 0; for k in h { for v in h { result += k * 10 + v } }; result;`, 66},
+		{`let a = [1, 2, 3]
+func f(n) {
+	let total = 0
+	for x in a {
+		total += x
+		if (n > 0) { total += f(n - 1) }
+	}
+	return total
+}
+f(2);`, 78},
 	}
 
 	for _, tt := range tests {
@@ -885,6 +1832,30 @@ func TestForLoopStatement(t *testing.T) {
 	}
 }
 
+func TestLoopStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let i = 0; for i < 5 { i += 1 }; i;", 5},
+		{"let i = 0; while i < 5 { i += 1 }; i;", 5},
+		{"let sum = 0; for let i = 0; i < 5; i += 1 { sum += i }; sum;", 10},
+		{"let i = 0; for { i += 1; if (i == 3) { break } }; i;", 3},
+		{"let sum = 0; for let i = 0; i < 5; i += 1 { if (i == 2) { continue }; sum += i }; sum;", 8},
+		{"let sum = 0; for v in [1, 2, 3, 4] { if (v == 3) { break }; sum += v }; sum;", 3},
+		{"let sum = 0; for v in [1, 2, 3, 4] { if (v == 3) { continue }; sum += v }; sum;", 7},
+		{"let n = 0; outer: for i in [1, 2, 3] { for j in [1, 2, 3] { if (j == 2) { continue outer }; n += 1 } }; n;", 3},
+		{"let n = 0; outer: for { while true { n += 1; break outer } }; n;", 1},
+		{"func first(a) { for v in a { if (v > 1) { return v } }; return 0 }; first([1, 5, 7]);", 5},
+		{"func f() { let i = 0; for { i += 1; if (i == 4) { return i } } }; f();", 4},
+		{"func f() { for let i = 0; i < 10; i += 1 { while true { return i + 10 } } }; f();", 10},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
 func testEval(input string) object.Object {
 	l := lexer.New(input)
 	p := parser.New(l)
@@ -0,0 +1,293 @@
+package evaluator
+
+import (
+	"pythia/object"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestGenerator(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			`func count(n) {
+	let i = 0
+	while i < n {
+		yield i
+		i += 1
+	}
+}
+let result = []
+for v in count(3) { result = append(result, v) }
+result`,
+			"[0, 1, 2]",
+		},
+		{
+			`func pairs() { yield "a"; yield "b" }
+let result = []
+for i, v in pairs() { result = append(result, [i, v]) }
+result`,
+			"[[0, a], [1, b]]",
+		},
+		{
+			`let g = func() { yield 1; yield; return 5; yield 3 }()
+let result = [next(g), next(g), next(g, "end"), next(g, "end")]
+result`,
+			"[1, null, end, end]",
+		},
+		{
+			`func fib() {
+	let a = 0
+	let b = 1
+	for {
+		yield a
+		let t = a + b
+		a = b
+		b = t
+	}
+}
+let result = []
+for v in fib() {
+	if (v > 20) { break }
+	result = append(result, v)
+}
+result`,
+			"[0, 1, 1, 2, 3, 5, 8, 13]",
+		},
+		{
+			`func inner() { yield 1; yield 2 }
+func outer() {
+	for v in inner() { yield v * 10 }
+	yield 30
+}
+let result = []
+for v in outer() { result = append(result, v) }
+result`,
+			"[10, 20, 30]",
+		},
+		{
+			`func g() {
+	try {
+		yield 1
+		throw "boom"
+	} catch (e) {
+		yield e.message()
+	} finally {
+		yield "finally"
+	}
+}
+let result = []
+for v in g() { result = append(result, v) }
+result`,
+			"[1, boom, finally]",
+		},
+		{
+			`let g = func() { yield 1 }()
+let h = g
+let result = [next(g), next(h, "shared")]
+result`,
+			"[1, shared]",
+		},
+		{
+			`let cleaned = false
+func g() {
+	try { yield 1; yield 2 } finally { cleaned = true }
+}
+for v in g() { break }
+cleaned`,
+			"false",
+		},
+		{`type(func() { yield 1 }())`, "Type: GENERATOR"},
+		{`func g() { yield 1 }; g()`, "<generator g>"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. expected=%s, got=%v", tt.input, tt.expected, evaluated)
+		}
+	}
+}
+
+func TestUserDefinedIterable(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			`func counter(n) {
+	let i = 0
+	return {"next": func() {
+		if (i >= n) { throw error("StopIteration", "") }
+		i += 1
+		return i
+	}}
+}
+let c = {"__iter__": func() { return counter(3) }}
+let result = []
+for v in c { result = append(result, v) }
+for i, v in c { result = append(result, i) }
+result`,
+			"[1, 2, 3, 0, 1, 2]",
+		},
+		{
+			`let c = {"__iter__": func() { yield "x"; yield "y" }}
+let result = ""
+for v in c { result += v }
+result`,
+			"xy",
+		},
+		{
+			`let c = {"__iter__": func() { return [3, 4] }}
+let result = 0
+for v in c { result += v }
+result`,
+			"7",
+		},
+		{
+			`let h = {"next": 1, "__iter__": 2}
+let result = []
+for k in h { result = append(result, k) }
+result`,
+			"[next, __iter__]",
+		},
+		{
+			`func counter(n) {
+	let i = 0
+	return {"next": func() {
+		if (i >= n) { throw error("StopIteration", "") }
+		i += 1
+		return i
+	}}
+}
+let c = {"__iter__": func() { return counter(3) }}
+let it = iter(c)
+let result = [next(it), array(it), array(c)]
+result`,
+			"[1, [2, 3], [1, 2, 3]]",
+		},
+		{`array({"__iter__": func() { yield "x"; yield "y" }})`, "[x, y]"},
+		{`array({"a": 1, "b": 2})`, "[a, b]"},
+		{`let it = iter([1, 2]); next(it) + next(it)`, "3"},
+		{`let it = iter("ab"); next(it); next(it, "end"); next(it, "end")`, "end"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. expected=%s, got=%v", tt.input, tt.expected, evaluated)
+		}
+	}
+}
+
+func TestGeneratorErrors(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedKind  object.ErrorKind
+		expectedError string
+	}{
+		{"let g = func() { yield 1 }(); next(g); next(g)", object.STOP_ITERATION, "iterator is exhausted"},
+		{"next([1])", object.TYPE_ERROR, "argument to next must be iterator, got ARRAY"},
+		{"func g(a) { yield a }; g()", object.TYPE_ERROR, "wrong number of arguments. got=0, want=1"},
+		{`func g() { yield 1; throw error("ValueError", "bad") }; for v in g() {}`, object.VALUE_ERROR, "bad"},
+		{`let g = func() { yield next(g) }(); next(g)`, object.VALUE_ERROR, "generator already executing"},
+		{`for v in {"__iter__": func() { return 1 }} {}`, object.TYPE_ERROR, "__iter__ returned non-iterator of type INTEGER"},
+		{`for v in {"__iter__": func() { return {"next": func() { return -true }} }} {}`, object.TYPE_ERROR, "unknown operator: -BOOLEAN"},
+		{`for v in {"__iter__": func() { throw "no" }} {}`, object.ERROR, "no"},
+		{"for v in 1 {}", object.TYPE_ERROR, "INTEGER object doesn't implement the Iterable interface"},
+		{"iter(1)", object.TYPE_ERROR, "INTEGER object doesn't implement the Iterable interface"},
+		{"iter()", object.TYPE_ERROR, "wrong number of arguments. got=0, want=1"},
+		{`array({"__iter__": func() { throw "no" }})`, object.ERROR, "no"},
+		{`iter({"__iter__": func() { return 1 }})`, object.TYPE_ERROR, "__iter__ returned non-iterator of type INTEGER"},
+		{`array({"__iter__": func() { return {"next": func() { return -true }} }})`, object.TYPE_ERROR, "unknown operator: -BOOLEAN"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned for %q. got=%T(%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if errObj.Kind != tt.expectedKind || errObj.Message != tt.expectedError {
+			t.Errorf("wrong error for %q. expected=%s: %s, got=%s", tt.input, tt.expectedKind, tt.expectedError, errObj.Inspect())
+		}
+	}
+}
+
+func TestGeneratorTrace(t *testing.T) {
+	input := `func g() {
+  yield 1
+  yield -true
+}
+let it = g()
+next(it)
+for v in it {}`
+
+	evaluated := testEval(input)
+
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+	}
+
+	traceback := []string{"line 7, in <module>", "line 3, in g"}
+	frames := errObj.Traceback()
+	if len(frames) != len(traceback) {
+		t.Fatalf("wrong traceback length. expected=%d, got=%d", len(traceback), len(frames))
+	}
+	for i, frame := range frames {
+		if frame.String() != traceback[i] {
+			t.Errorf("traceback[%d] is wrong. expected=%q, got=%q", i, traceback[i], frame.String())
+		}
+	}
+}
+
+// a builtin records the function of user-defined iterable where the builtin is called, like a for-loop
+func TestBuiltinIterableTrace(t *testing.T) {
+	input := `func start() {
+  throw "no"
+}
+let c = {"__iter__": start}
+array(c)`
+
+	errObj, ok := testEval(input).(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned")
+	}
+
+	traceback := []string{"line 5, in <module>", "line 2, in start"}
+	frames := errObj.Traceback()
+	if len(frames) != len(traceback) {
+		t.Fatalf("wrong traceback length. expected=%d, got=%d", len(traceback), len(frames))
+	}
+	for i, frame := range frames {
+		if frame.String() != traceback[i] {
+			t.Errorf("traceback[%d] is wrong. expected=%q, got=%q", i, traceback[i], frame.String())
+		}
+	}
+}
+
+func TestAbandonedGeneratorExits(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	testEval(`func g() { for { yield 1 } }
+for let i = 0; i < 100; i += 1 {
+	let it = g()
+	next(it)
+}`)
+
+	// goroutines of abandoned generators exit when the generators are garbage collected
+	for i := 0; i < 100 && runtime.NumGoroutine() > before+10; i++ {
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if runtime.NumGoroutine() > before+10 {
+		t.Errorf("goroutines of abandoned generators are not finished. before=%d, after=%d", before, runtime.NumGoroutine())
+	}
+}
@@ -0,0 +1,113 @@
+package evaluator
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"pythia/evaluator"
+	"pythia/object"
+	"strings"
+	"testing"
+)
+
+// writeModules writes module files in a new directory, and adds the directory to search paths
+func writeModules(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "pythia")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, source := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(source), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	evaluator.SetSearchPaths(dir)
+
+	return dir
+}
+
+func TestImportModule(t *testing.T) {
+	dir := writeModules(t, map[string]string{
+		"lib/math.pyt": `let pi = 3
+func add(a, b) { return a + b }
+let double = func(x) { return helper.twice(x) }
+import "helper"`,
+		"lib/helper.pyt": `func twice(x) { return x * 2 }`,
+		"counter.pyt": `let count = 0
+func inc() { count += 1; return count }`,
+	})
+	defer os.RemoveAll(dir)
+	defer evaluator.SetSearchPaths()
+
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{`import "lib/math.pyt" as m; m.pi`, 3},
+		{`import "lib/math" as m; m.add(1, 2)`, 3},
+		{`import "lib/math"; math.pi * 2`, 6},
+		{`import "lib/math" as m; m.double(4)`, 8},
+		{`from "lib/math" import add, pi; add(pi, 1)`, 4},
+		{`from "lib/helper" import twice; let f = twice; f(5)`, 10},
+		{`import "counter" as a; import "counter" as b; a.inc(); b.inc()`, 2},
+		{`import "counter" as c; c.count`, 2},
+		{`import "` + filepath.Join(dir, "lib", "helper.pyt") + `" as h; h.twice(1)`, 2},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestImportModuleErrors(t *testing.T) {
+	dir := writeModules(t, map[string]string{
+		"a.pyt":      `import "b"`,
+		"b.pyt":      `import "c"`,
+		"c.pyt":      `import "a"`,
+		"self.pyt":   `import "self"`,
+		"broken.pyt": `let = 1`,
+		"raise.pyt":  `let x = 1 + true`,
+		"ok.pyt":     `let x = 1`,
+	})
+	defer os.RemoveAll(dir)
+	defer evaluator.SetSearchPaths()
+
+	tests := []struct {
+		input           string
+		expectedKind    object.ErrorKind
+		expectedMessage string
+	}{
+		{`import "missing"`, object.IMPORT_ERROR, "no module found: missing"},
+		{`import "a"`, object.IMPORT_ERROR, "circular import: " + strings.Join([]string{
+			filepath.Join(dir, "a.pyt"), filepath.Join(dir, "b.pyt"), filepath.Join(dir, "c.pyt"), filepath.Join(dir, "a.pyt"),
+		}, " -> ")},
+		{`import "self"`, object.IMPORT_ERROR, "circular import: " + filepath.Join(dir, "self.pyt") + " -> " + filepath.Join(dir, "self.pyt")},
+		{`import "broken"`, object.IMPORT_ERROR, "can't parse module broken: expected next token to be IDENT, got = instead at " + filepath.Join(dir, "broken.pyt") + ", line 1, column 5"},
+		{`import "raise"`, object.TYPE_ERROR, "type mismatch: INTEGER + BOOLEAN"},
+		{`from "ok" import y`, object.IMPORT_ERROR, "cannot import name y from ok"},
+		{`import "ok"; ok.y`, object.ATTRIBUTE_ERROR, "module ok has no attribute y"},
+		{`import "ok"; ok.f()`, object.ATTRIBUTE_ERROR, "module ok has no attribute f"},
+		{`let a = 1; a.x`, object.ATTRIBUTE_ERROR, "INTEGER has no attribute x"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned for %q. got=%T(%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+
+		if errObj.Kind != tt.expectedKind || errObj.Message != tt.expectedMessage {
+			t.Errorf("wrong error for %q. expected=%s: %q, got=%s: %q",
+				tt.input, tt.expectedKind, tt.expectedMessage, errObj.Kind, errObj.Message)
+		}
+	}
+}
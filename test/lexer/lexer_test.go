@@ -102,7 +102,7 @@ func TestFunctionToken(t *testing.T) {
 
 func TestOperatorToken(t *testing.T) {
 	input := `
-	!-/*%5;
+	!-/ *%5;
 
 	5 < 10 > 5;
 	10 == 10;
@@ -418,3 +418,266 @@ func TestDotToken(t *testing.T) {
 		}
 	}
 }
+
+func TestUnicodeIdentifierToken(t *testing.T) {
+	input := `let 이름 = "값"; café_2 + x١ + _π`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.LET, "let"},
+		{token.IDENT, "이름"},
+		{token.ASSIGN, "="},
+		{token.STRING, "값"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "café_2"},
+		{token.PLUS, "+"},
+		{token.IDENT, "x١"},
+		{token.PLUS, "+"},
+		{token.IDENT, "_π"},
+		{token.EOF, ""},
+	}
+
+	l := lexer.New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	input := `// line comment
+	let a = 1; // trailing comment
+	/* block
+	   comment */ let b = a / 2;
+	/**/ a /*/ still comment */`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.LET, "let"},
+		{token.IDENT, "a"},
+		{token.ASSIGN, "="},
+		{token.INT, "1"},
+		{token.SEMICOLON, ";"},
+		{token.LET, "let"},
+		{token.IDENT, "b"},
+		{token.ASSIGN, "="},
+		{token.IDENT, "a"},
+		{token.SLASH, "/"},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "a"},
+		{token.EOF, ""},
+	}
+
+	l := lexer.New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+
+	if len(l.Errors()) != 0 {
+		t.Fatalf("lexer has errors: %v", l.Errors())
+	}
+}
+
+func TestUnterminatedBlockComment(t *testing.T) {
+	input := "let a = 1;\n  /* not closed"
+
+	l := lexer.New(input)
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+	}
+
+	expected := "unterminated block comment at line 2, column 3"
+	if len(l.Errors()) != 1 || l.Errors()[0] != expected {
+		t.Fatalf("wrong lexer errors. expected=%q, got=%v", expected, l.Errors())
+	}
+}
+
+func TestStringEscapeToken(t *testing.T) {
+	input := "\"a\\nb\" \"tab\\there\" \"say \\\"hi\\\"\" \"back\\\\slash\" \"\\uD55C\" \"\\x41\" `raw\\n\nline`"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.STRING, "a\nb"},
+		{token.STRING, "tab\there"},
+		{token.STRING, `say "hi"`},
+		{token.STRING, `back\slash`},
+		{token.STRING, "한"},
+		{token.STRING, "A"},
+		{token.STRING, "raw\\n\nline"},
+		{token.EOF, ""},
+	}
+
+	l := lexer.New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+
+	if len(l.Errors()) != 0 {
+		t.Fatalf("lexer has errors: %v", l.Errors())
+	}
+}
+
+func TestIllegalStringToken(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{`let s = "abc`, "unterminated string literal at line 1, column 9"},
+		{"let s = `abc", "unterminated raw string literal at line 1, column 9"},
+		{`"a\qb"`, `unknown escape sequence \q at line 1, column 3`},
+		{`"\x4"`, `invalid escape sequence \x4, want 2 hex digits at line 1, column 2`},
+		{"\n @", `illegal character '@' at line 2, column 2`},
+		{`"abc\`, "unterminated string literal at line 1, column 1"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+
+		illegal := false
+		for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+			if tok.Type == token.ILLEGAL {
+				illegal = true
+			}
+		}
+
+		if !illegal {
+			t.Errorf("no ILLEGAL token for %q", tt.input)
+		}
+		if len(l.Errors()) == 0 || l.Errors()[0] != tt.expectedError {
+			t.Errorf("wrong lexer errors for %q. expected=%q, got=%v", tt.input, tt.expectedError, l.Errors())
+		}
+	}
+}
+
+func TestNumberLiteralToken(t *testing.T) {
+	input := `0xFF 0o755 0b1010 1_000_000 6.02e23 1.5E-3 2e+2 0.5 [1].last`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.INT, "0xFF"},
+		{token.INT, "0o755"},
+		{token.INT, "0b1010"},
+		{token.INT, "1_000_000"},
+		{token.FLOAT, "6.02e23"},
+		{token.FLOAT, "1.5E-3"},
+		{token.FLOAT, "2e+2"},
+		{token.FLOAT, "0.5"},
+		{token.LBRACKET, "["},
+		{token.INT, "1"},
+		{token.RBRACKET, "]"},
+		{token.DOT, "."},
+		{token.IDENT, "last"},
+		{token.EOF, ""},
+	}
+
+	l := lexer.New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+
+	if len(l.Errors()) != 0 {
+		t.Fatalf("lexer has errors: %v", l.Errors())
+	}
+}
+
+func TestIllegalNumberToken(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedLiteral string
+		expectedError   string
+	}{
+		{"1.2.3", "1.2.3", "invalid character '.' in number literal at line 1, column 4"},
+		{"0x", "0x", "hexadecimal literal has no digits at line 1, column 3"},
+		{"0b102", "0b102", "invalid character '2' in number literal at line 1, column 5"},
+		{"0o8", "0o8", "octal literal has no digits at line 1, column 3"},
+		{"1__000", "1__000", "'_' must separate successive digits at line 1, column 2"},
+		{"100_", "100_", "'_' must separate successive digits at line 1, column 4"},
+		{"1e", "1e", "exponent has no digits at line 1, column 3"},
+		{"12abc", "12abc", "invalid character 'a' in number literal at line 1, column 3"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+
+		tok := l.NextToken()
+		if tok.Type != token.ILLEGAL {
+			t.Errorf("tokentype wrong for %q. expected=%q, got=%q", tt.input, token.ILLEGAL, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Errorf("literal wrong for %q. expected=%q, got=%q", tt.input, tt.expectedLiteral, tok.Literal)
+		}
+		if next := l.NextToken(); next.Type != token.EOF {
+			t.Errorf("malformed literal %q is not consumed. got=%q", tt.input, next.Literal)
+		}
+		if len(l.Errors()) != 1 || l.Errors()[0] != tt.expectedError {
+			t.Errorf("wrong lexer errors for %q. expected=%q, got=%v", tt.input, tt.expectedError, l.Errors())
+		}
+	}
+}
+
+func TestTokenPosition(t *testing.T) {
+	input := "let a = \"한글\";\n\tfoo(a)"
+
+	tests := []struct {
+		expectedType     token.TokenType
+		expectedPosition token.Position
+	}{
+		{token.LET, token.Position{Filename: "main.pyt", Offset: 0, Line: 1, Column: 1}},
+		{token.IDENT, token.Position{Filename: "main.pyt", Offset: 4, Line: 1, Column: 5}},
+		{token.ASSIGN, token.Position{Filename: "main.pyt", Offset: 6, Line: 1, Column: 7}},
+		{token.STRING, token.Position{Filename: "main.pyt", Offset: 8, Line: 1, Column: 9}},
+		{token.SEMICOLON, token.Position{Filename: "main.pyt", Offset: 16, Line: 1, Column: 13}},
+		{token.IDENT, token.Position{Filename: "main.pyt", Offset: 19, Line: 2, Column: 2}},
+		{token.LPAREN, token.Position{Filename: "main.pyt", Offset: 22, Line: 2, Column: 5}},
+		{token.IDENT, token.Position{Filename: "main.pyt", Offset: 23, Line: 2, Column: 6}},
+		{token.RPAREN, token.Position{Filename: "main.pyt", Offset: 24, Line: 2, Column: 7}},
+		{token.EOF, token.Position{Filename: "main.pyt", Offset: 25, Line: 2, Column: 8}},
+	}
+
+	l := lexer.NewFile("main.pyt", input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Pos != tt.expectedPosition {
+			t.Fatalf("tests[%d] - position wrong. expected=%+v, got=%+v", i, tt.expectedPosition, tok.Pos)
+		}
+	}
+}
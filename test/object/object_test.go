@@ -1,7 +1,10 @@
 package object
 
 import (
+	"math"
+	"math/big"
 	"pythia/object"
+	"strings"
 	"testing"
 )
 
@@ -19,3 +22,166 @@ func TestStringHashKey(t *testing.T) {
 		t.Errorf("strings with same content ahve different hash keys")
 	}
 }
+
+func TestBigIntHashKey(t *testing.T) {
+	big1 := &object.BigInt{Value: new(big.Int).Lsh(big.NewInt(1), 70)}
+	big2 := &object.BigInt{Value: new(big.Int).Lsh(big.NewInt(1), 70)}
+	negative := &object.BigInt{Value: new(big.Int).Neg(big1.Value)}
+
+	if big1.HashKey() != big2.HashKey() || !big1.Equals(big2) {
+		t.Errorf("big integers with same value have different hash keys")
+	}
+
+	if big1.HashKey() == negative.HashKey() || big1.Equals(negative) {
+		t.Errorf("big integers with different sign have same hash keys")
+	}
+
+	if big1.Type() != object.INTEGER_OBJ {
+		t.Errorf("big integer has wrong type. got=%s", big1.Type())
+	}
+}
+
+func TestNewInteger(t *testing.T) {
+	tests := []struct {
+		value    *big.Int
+		expected object.Object
+	}{
+		{big.NewInt(42), &object.Integer{Value: 42}},
+		{new(big.Int).Lsh(big.NewInt(1), 63), &object.BigInt{Value: new(big.Int).Lsh(big.NewInt(1), 63)}},
+		{new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), 63)), &object.Integer{Value: -1 << 63}},
+	}
+
+	for _, tt := range tests {
+		result := object.NewInteger(tt.value)
+		if result.Inspect() != tt.expected.Inspect() || !result.Equals(tt.expected) {
+			t.Errorf("wrong integer for %s. expected=%T(%s), got=%T(%s)", tt.value, tt.expected, tt.expected.Inspect(), result, result.Inspect())
+		}
+	}
+}
+
+func TestHashOrder(t *testing.T) {
+	hash := object.NewHash()
+	for _, key := range []string{"c", "a", "b"} {
+		str := &object.String{Value: key}
+		hash.Set(str.HashKey(), object.HashPair{Key: str, Value: &object.Integer{Value: 1}})
+	}
+
+	a := &object.String{Value: "a"}
+	hash.Set(a.HashKey(), object.HashPair{Key: a, Value: &object.Integer{Value: 2}})
+	if hash.Inspect() != "{c: 1, a: 2, b: 1}" {
+		t.Errorf("updating value changed order. got=%s", hash.Inspect())
+	}
+
+	iter := hash.Iter()
+	key, _, _ := iter.Next()
+	if key.Inspect() != "c" {
+		t.Fatalf("wrong first key. got=%s", key.Inspect())
+	}
+
+	// the entry which iteration stopped at is deleted
+	c := &object.String{Value: "c"}
+	if !hash.Delete(c.HashKey()) || hash.Delete(c.HashKey()) {
+		t.Errorf("wrong result of Delete")
+	}
+
+	var keys []string
+	for key, _, ok := iter.Next(); ok; key, _, ok = iter.Next() {
+		keys = append(keys, key.Inspect())
+	}
+	if strings.Join(keys, ",") != "a,b" {
+		t.Errorf("wrong keys after deletion. got=%v", keys)
+	}
+
+	if hash.Len() != 2 || hash.Inspect() != "{a: 2, b: 1}" {
+		t.Errorf("wrong hash after deletion. got=%s", hash.Inspect())
+	}
+}
+
+func TestHashIteratorVisitsKeysAddedAfterDeletion(t *testing.T) {
+	hash := object.NewHash()
+	set := func(key string) {
+		str := &object.String{Value: key}
+		hash.Set(str.HashKey(), object.HashPair{Key: str, Value: &object.Integer{Value: 1}})
+	}
+	remove := func(key string) {
+		hash.Delete((&object.String{Value: key}).HashKey())
+	}
+	set("a")
+	set("b")
+
+	iter := hash.Iter()
+	key, _, _ := iter.Next()
+	if key.Inspect() != "a" {
+		t.Fatalf("wrong first key. got=%s", key.Inspect())
+	}
+
+	// the entry at the cursor, then the tail are deleted, and a new key is added
+	remove("a")
+	remove("b")
+	set("c")
+
+	var keys []string
+	for key, _, ok := iter.Next(); ok; key, _, ok = iter.Next() {
+		keys = append(keys, key.Inspect())
+	}
+	if strings.Join(keys, ",") != "c" {
+		t.Errorf("wrong keys after deletion. got=%v", keys)
+	}
+}
+
+func TestRange(t *testing.T) {
+	tests := []struct {
+		rng      *object.Range
+		expected []int64
+	}{
+		{&object.Range{Start: 0, Stop: 5, Step: 2}, []int64{0, 2, 4}},
+		{&object.Range{Start: 5, Stop: 0, Step: -2}, []int64{5, 3, 1}},
+		{&object.Range{Start: 1, Stop: 1, Step: 1}, []int64{}},
+		{&object.Range{Start: math.MaxInt64 - 1, Stop: math.MaxInt64, Step: 1}, []int64{math.MaxInt64 - 1}},
+		{&object.Range{Start: math.MinInt64, Stop: math.MaxInt64, Step: math.MaxInt64}, []int64{math.MinInt64, -1, math.MaxInt64 - 1}},
+	}
+
+	for _, tt := range tests {
+		if tt.rng.Len() != uint64(len(tt.expected)) {
+			t.Errorf("wrong length of %s. expected=%d, got=%d", tt.rng.Inspect(), len(tt.expected), tt.rng.Len())
+			continue
+		}
+
+		iter := tt.rng.Iter()
+		for i, expected := range tt.expected {
+			if tt.rng.At(uint64(i)) != expected {
+				t.Errorf("wrong element %d of %s. expected=%d, got=%d", i, tt.rng.Inspect(), expected, tt.rng.At(uint64(i)))
+			}
+			if !tt.rng.Contains(expected) {
+				t.Errorf("%s doesn't contain %d", tt.rng.Inspect(), expected)
+			}
+
+			val, idx, ok := iter.Next()
+			if !ok || !val.Equals(&object.Integer{Value: expected}) || !idx.Equals(&object.Integer{Value: int64(i)}) {
+				t.Errorf("wrong iteration %d of %s. got=%v, %v, %t", i, tt.rng.Inspect(), val, idx, ok)
+			}
+		}
+		if _, _, ok := iter.Next(); ok {
+			t.Errorf("iterator of %s is not exhausted", tt.rng.Inspect())
+		}
+	}
+}
+
+func TestExhaustedIteratorReturnsSharedNull(t *testing.T) {
+	tests := []object.Iterator{
+		(&object.Array{}).Iter(),
+		(&object.String{}).Iter(),
+		(&object.Range{Start: 0, Stop: 0, Step: 1}).Iter(),
+		object.NewHash().Iter(),
+		&object.Generator{Name: "g", Step: func() (object.Object, bool) { return nil, false }},
+	}
+
+	for _, iter := range tests {
+		for i := 0; i < 2; i++ { // the first Next finishes generator, the second one returns after it is done
+			val, idx, ok := iter.Next()
+			if ok || val != object.NULL || idx != object.NULL {
+				t.Errorf("wrong result of exhausted %T. got=(%v, %v, %t)", iter, val, idx, ok)
+			}
+		}
+	}
+}
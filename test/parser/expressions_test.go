@@ -297,6 +297,22 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"add(a * b[2], b[1], 2 * [1, 2][1])",
 			"add((a * (b[2])), (b[1]), (2 * ([1, 2][1])))",
 		},
+		{
+			"a < b ? a + 1 : b",
+			"((a < b) ? (a + 1) : b)",
+		},
+		{
+			"a || b ? c : d",
+			"((a || b) ? c : d)",
+		},
+		{
+			"a ? b : c ? d : e",
+			"(a ? b : (c ? d : e))",
+		},
+		{
+			"x = a ? b : c",
+			"x = (a ? b : c)",
+		},
 	}
 
 	for _, tt := range tests {
@@ -408,6 +424,38 @@ func TestParsingIndexExpression(t *testing.T) {
 	}
 }
 
+func TestParsingSliceExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a[1:2]", "(a[1:2])"},
+		{"a[1:2:3]", "(a[1:2:3])"},
+		{"a[:]", "(a[:])"},
+		{"a[::]", "(a[:])"},
+		{"a[::-1]", "(a[::(-1)])"},
+		{"a[1:]", "(a[1:])"},
+		{"a[:i + 1]", "(a[:(i + 1)])"},
+		{"a[x ? 1 : 2:]", "(a[(x ? 1 : 2):])"},
+		{"a[1:][0]", "((a[1:])[0])"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := parser.New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		if _, ok := stmt.Expression.(*ast.SliceExpression); !ok && tt.input != "a[1:][0]" {
+			t.Errorf("exp not *ast.SliceExpression for %q. got=%T", tt.input, stmt.Expression)
+		}
+		if stmt.Expression.String() != tt.expected {
+			t.Errorf("wrong expression for %q. expected=%q, got=%q", tt.input, tt.expected, stmt.Expression.String())
+		}
+	}
+}
+
 func TestObjectMethodCallExpression(t *testing.T) {
 	input := `obj.call(1, 2+3)`
 
@@ -447,3 +495,36 @@ func TestObjectMethodCallExpression(t *testing.T) {
 	testLiteralExpression(t, call.Arguments[0], 1)
 	testInfixExpression(t, call.Arguments[1], 2, "+", 3)
 }
+
+func TestAttributeExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"m.pi", "m.pi"},
+		{"m.pi * 2", "(m.pi * 2)"},
+		{"a.b.c()", "a.b.c()"},
+		{"m.list[0]", "(m.list[0])"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := parser.New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("wrong expression for %q. expected=%q, got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+
+	program := parser.New(lexer.New("m.pi")).ParseProgram()
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+
+	exp, ok := stmt.Expression.(*ast.AttributeExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.AttributeExpression. got=%T", stmt.Expression)
+	}
+	testIdentifier(t, exp.Object, "m")
+	testIdentifier(t, exp.Name, "pi")
+}
@@ -3,6 +3,7 @@ package parser
 import (
 	"fmt"
 	"pythia/ast"
+	"pythia/lexer"
 	"pythia/parser"
 	"testing"
 )
@@ -149,3 +150,54 @@ func testBooleanLiteral(t *testing.T, exp ast.Expression, value bool) bool {
 
 	return true
 }
+
+func TestNodePosition(t *testing.T) {
+	input := "let a = 1;\nfoo(a + 2)"
+
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	tests := []struct {
+		node         ast.Node
+		expectedLine int
+		expectedCol  int
+	}{
+		{program, 1, 1},
+		{program.Statements[0], 1, 1},
+		{program.Statements[0].(*ast.LetStatement).Name, 1, 5},
+		{program.Statements[0].(*ast.LetStatement).Value, 1, 9},
+		{program.Statements[1], 2, 1},
+		{program.Statements[1].(*ast.ExpressionStatement).Expression, 2, 4},
+		{program.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.CallExpression).Arguments[0], 2, 7},
+	}
+
+	for i, tt := range tests {
+		pos := tt.node.Pos()
+		if pos.Line != tt.expectedLine || pos.Column != tt.expectedCol {
+			t.Errorf("tests[%d] - position wrong. expected=%d:%d, got=%d:%d", i, tt.expectedLine, tt.expectedCol, pos.Line, pos.Column)
+		}
+	}
+}
+
+func TestParserErrorPosition(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let = 1;", "expected next token to be IDENT, got = instead at line 1, column 5"},
+		{"let a = 1;\nlet b 2;", "expected next token to be =, got INT instead at line 2, column 7"},
+		{"1 +\n  ;", "no prefix parse function for ; found at line 2, column 3"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := parser.New(l)
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 || p.Errors()[0] != tt.expected {
+			t.Errorf("wrong parser errors for %q. expected=%q, got=%q", tt.input, tt.expected, p.Errors())
+		}
+	}
+}
@@ -273,3 +273,347 @@ func TestInstruction(t *testing.T) {
 		t.Fatalf("ast.InstructionStatement.Instruction is not expected. got=%s\n", inst.Instruction)
 	}
 }
+
+func TestLoopStatement(t *testing.T) {
+	tests := []struct {
+		input             string
+		expectedInit      string
+		expectedCondition string
+		expectedPost      string
+	}{
+		{"for { x }", "", "", ""},
+		{"for i < 10 { x }", "", "(i < 10)", ""},
+		{"while i < 10 { x }", "", "(i < 10)", ""},
+		{"for let i = 0; i < 10; i += 1 { x }", "let i = 0;", "(i < 10)", "i += 1"},
+		{"for i = 0; i < 10; i += 1 { x }", "i = 0", "(i < 10)", "i += 1"},
+		{"for ; ; { x }", "", "", ""},
+		{"for ; i < 10; { x }", "", "(i < 10)", ""},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := parser.New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program has wrong number of statements for %q. got=%d", tt.input, len(program.Statements))
+		}
+		stmt, ok := program.Statements[0].(*ast.LoopStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.LoopStatement. got=%T", program.Statements[0])
+		}
+
+		if stmt.Init == nil && tt.expectedInit != "" || stmt.Init != nil && stmt.Init.String() != tt.expectedInit {
+			t.Errorf("wrong init for %q. expected=%q, got=%+v", tt.input, tt.expectedInit, stmt.Init)
+		}
+		if stmt.Condition == nil && tt.expectedCondition != "" || stmt.Condition != nil && stmt.Condition.String() != tt.expectedCondition {
+			t.Errorf("wrong condition for %q. expected=%q, got=%+v", tt.input, tt.expectedCondition, stmt.Condition)
+		}
+		if stmt.Post == nil && tt.expectedPost != "" || stmt.Post != nil && stmt.Post.String() != tt.expectedPost {
+			t.Errorf("wrong post for %q. expected=%q, got=%+v", tt.input, tt.expectedPost, stmt.Post)
+		}
+		if len(stmt.Body.Statements) != 1 {
+			t.Errorf("wrong body for %q. got=%d statements", tt.input, len(stmt.Body.Statements))
+		}
+	}
+}
+
+func TestBranchStatement(t *testing.T) {
+	input := `outer: for i in a { for { if (i) { break outer } else { continue } } }`
+
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.ForStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ForStatement. got=%T", program.Statements[0])
+	}
+	testIdentifier(t, stmt.Label, "outer")
+
+	inner := stmt.Body.Statements[0].(*ast.LoopStatement)
+	ifStmt := inner.Body.Statements[0].(*ast.IfStatement)
+
+	breakStmt, ok := ifStmt.Consequence.Statements[0].(*ast.BranchStatement)
+	if !ok {
+		t.Fatalf("consequence is not ast.BranchStatement. got=%T", ifStmt.Consequence.Statements[0])
+	}
+	if breakStmt.TokenLiteral() != "break" {
+		t.Errorf("breakStmt.TokenLiteral not 'break'. got=%q", breakStmt.TokenLiteral())
+	}
+	testIdentifier(t, breakStmt.Label, "outer")
+
+	continueStmt, ok := ifStmt.Alternative.Statements[0].(*ast.BranchStatement)
+	if !ok {
+		t.Fatalf("alternative is not ast.BranchStatement. got=%T", ifStmt.Alternative.Statements[0])
+	}
+	if continueStmt.TokenLiteral() != "continue" || continueStmt.Label != nil {
+		t.Errorf("wrong continue statement. got=%q", continueStmt.String())
+	}
+}
+
+func TestBranchStatementErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"break", "break is not in a loop at line 1, column 1"},
+		{"for { func f() { continue } }", "continue is not in a loop at line 1, column 18"},
+		{"a: for { break b }", "break label not defined: b at line 1, column 16"},
+		{"a: let x = 1", "label a must be followed by loop, got LET at line 1, column 4"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := parser.New(l)
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 || p.Errors()[0] != tt.expected {
+			t.Errorf("wrong parser errors for %q. expected=%q, got=%q", tt.input, tt.expected, p.Errors())
+		}
+	}
+}
+
+func TestElseIfStatement(t *testing.T) {
+	input := `if (x < y) { x } else if (x > y) { y } else { z }`
+
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d\n", 1, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.IfStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.IfStatement. got=%T", program.Statements[0])
+	}
+
+	if len(stmt.Alternative.Statements) != 1 {
+		t.Fatalf("alternative is not 1 statements. got=%d\n", len(stmt.Alternative.Statements))
+	}
+
+	elseIf, ok := stmt.Alternative.Statements[0].(*ast.IfStatement)
+	if !ok {
+		t.Fatalf("alternative is not ast.IfStatement. got=%T", stmt.Alternative.Statements[0])
+	}
+
+	if !testInfixExpression(t, elseIf.Condition, "x", ">", "y") {
+		return
+	}
+
+	alternative, ok := elseIf.Alternative.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("else-if alternative is not ast.ExpressionStatement. got=%T", elseIf.Alternative.Statements[0])
+	}
+	testIdentifier(t, alternative.Expression, "z")
+}
+
+func TestTryStatement(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedParam string
+		hasCatch      bool
+		hasFinally    bool
+	}{
+		{"try { x } catch (e) { y }", "e", true, false},
+		{"try { x } catch { y }", "", true, false},
+		{"try { x } finally { z }", "", false, true},
+		{"try { x } catch (err) { y } finally { z }", "err", true, true},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := parser.New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.TryStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.TryStatement. got=%T", program.Statements[0])
+		}
+
+		if len(stmt.Body.Statements) != 1 {
+			t.Errorf("try body is not 1 statements. got=%d", len(stmt.Body.Statements))
+		}
+		if tt.expectedParam == "" {
+			if stmt.Param != nil {
+				t.Errorf("param is not nil. got=%q", stmt.Param.String())
+			}
+		} else {
+			testIdentifier(t, stmt.Param, tt.expectedParam)
+		}
+		if (stmt.Catch != nil) != tt.hasCatch {
+			t.Errorf("wrong catch block for %q. expected=%t", tt.input, tt.hasCatch)
+		}
+		if (stmt.Finally != nil) != tt.hasFinally {
+			t.Errorf("wrong finally block for %q. expected=%t", tt.input, tt.hasFinally)
+		}
+	}
+}
+
+func TestThrowStatement(t *testing.T) {
+	input := `throw error("ValueError", "bad");`
+
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.ThrowStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ThrowStatement. got=%T", program.Statements[0])
+	}
+
+	if stmt.Value.String() != `error(ValueError, bad)` {
+		t.Errorf("wrong thrown value. got=%q", stmt.Value.String())
+	}
+}
+
+func TestYieldStatement(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedValue string
+		isGenerator   bool
+	}{
+		{"func g() { yield 1 + 2 }", "(1 + 2)", true},
+		{"func g() { yield }", "", true},
+		{"func g() { let f = func() { yield x }; return f }", "x", false},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := parser.New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		fn, ok := program.Statements[0].(*ast.FunctionStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.FunctionStatement. got=%T", program.Statements[0])
+		}
+		if fn.IsGenerator != tt.isGenerator {
+			t.Errorf("wrong IsGenerator for %q. expected=%t, got=%t", tt.input, tt.isGenerator, fn.IsGenerator)
+		}
+
+		var ys *ast.YieldStatement
+		switch stmt := fn.Body.Statements[0].(type) {
+		case *ast.YieldStatement:
+			ys = stmt
+		case *ast.LetStatement:
+			lit := stmt.Value.(*ast.FunctionLiteral)
+			if !lit.IsGenerator {
+				t.Errorf("function literal is not generator for %q", tt.input)
+			}
+			ys = lit.Body.Statements[0].(*ast.YieldStatement)
+		default:
+			t.Fatalf("unexpected statement. got=%T", stmt)
+		}
+
+		value := ""
+		if ys.Value != nil {
+			value = ys.Value.String()
+		}
+		if value != tt.expectedValue {
+			t.Errorf("wrong yielded value for %q. expected=%q, got=%q", tt.input, tt.expectedValue, value)
+		}
+	}
+}
+
+func TestYieldStatementErrors(t *testing.T) {
+	l := lexer.New("let a = 1; yield a")
+	p := parser.New(l)
+	p.ParseProgram()
+
+	expected := "yield is not in a function at line 1, column 12"
+	if len(p.Errors()) == 0 || p.Errors()[0] != expected {
+		t.Errorf("wrong parser errors. expected=%q, got=%q", expected, p.Errors())
+	}
+}
+
+func TestTryStatementErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"try { x }", "try must be followed by catch or finally, got EOF at line 1, column 10"},
+		{"try { x } catch (1) { y }", "expected next token to be IDENT, got INT instead at line 1, column 18"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := parser.New(l)
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 || p.Errors()[0] != tt.expected {
+			t.Errorf("wrong parser errors for %q. expected=%q, got=%q", tt.input, tt.expected, p.Errors())
+		}
+	}
+}
+
+func TestImportStatement(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedPath  string
+		expectedAlias string
+		expectedNames []string
+	}{
+		{`import "lib/math.pyt" as m`, "lib/math.pyt", "m", nil},
+		{`import "strings";`, "strings", "", nil},
+		{`from "lib/math" import add, sub`, "lib/math", "", []string{"add", "sub"}},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := parser.New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.ImportStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ImportStatement. got=%T", program.Statements[0])
+		}
+
+		if stmt.Path.Value != tt.expectedPath {
+			t.Errorf("wrong path. expected=%q, got=%q", tt.expectedPath, stmt.Path.Value)
+		}
+		if tt.expectedAlias == "" {
+			if stmt.Alias != nil {
+				t.Errorf("alias is not nil. got=%q", stmt.Alias.String())
+			}
+		} else {
+			testIdentifier(t, stmt.Alias, tt.expectedAlias)
+		}
+		if len(stmt.Names) != len(tt.expectedNames) {
+			t.Fatalf("wrong number of names. expected=%d, got=%d", len(tt.expectedNames), len(stmt.Names))
+		}
+		for i, name := range tt.expectedNames {
+			testIdentifier(t, stmt.Names[i], name)
+		}
+	}
+}
+
+func TestImportStatementErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"import math", "expected next token to be STRING, got IDENT instead at line 1, column 8"},
+		{`import "math" as 1`, "expected next token to be IDENT, got INT instead at line 1, column 18"},
+		{`from "math" add`, "expected next token to be IMPORT, got IDENT instead at line 1, column 13"},
+		{`from "math" import a,`, "expected next token to be IDENT, got EOF instead at line 1, column 22"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := parser.New(l)
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 || p.Errors()[0] != tt.expected {
+			t.Errorf("wrong parser errors for %q. expected=%q, got=%q", tt.input, tt.expected, p.Errors())
+		}
+	}
+}
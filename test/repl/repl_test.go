@@ -0,0 +1,107 @@
+package repl
+
+import (
+	"bytes"
+	"pythia/repl"
+	"pythia/runner"
+	"strings"
+	"testing"
+)
+
+func TestIsComplete(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"let a = 1", true},
+		{"func add(a, b) {", false},
+		{"func add(a, b) {\n return a + b\n}", true},
+		{"add(1,", false},
+		{"[1, 2,\n 3]", true},
+		{`let s = "abc`, false},
+		{`let s = "a{b"`, true},
+		{`let s = "a\"{"`, true},
+		{`let s = "a\"`, false},
+		{"}", true},
+		{"let a = 1 // {", true},
+		{"let a = 1 /* {", false},
+		{"let a = 1 /* { */", true},
+		{"let a = 1 /*/ {", false},
+		{"let s = `raw {", false},
+		{"let s = `raw \\`", true},
+	}
+
+	for _, tt := range tests {
+		if repl.IsComplete(tt.input) != tt.expected {
+			t.Errorf("wrong completeness for %q. expected=%t", tt.input, tt.expected)
+		}
+	}
+}
+
+func TestStartMultiLine(t *testing.T) {
+	input := strings.Join([]string{
+		"func add(a, b) {",
+		"  return a + b",
+		"}",
+		"add(1,",
+		"2)",
+	}, "\n")
+
+	for _, engine := range []string{runner.ENGINE_EVAL, runner.ENGINE_VM} {
+		var out bytes.Buffer
+		repl.Start(strings.NewReader(input), &out, engine)
+
+		expected := ">> .. .. >> .. 3\n>> "
+		if out.String() != expected {
+			t.Errorf("wrong output with %s. expected=%q, got=%q", engine, expected, out.String())
+		}
+	}
+}
+
+func TestStartTraceback(t *testing.T) {
+	input := strings.Join([]string{
+		"func f(x) { return -x }",
+		"f(true)",
+	}, "\n")
+
+	for _, engine := range []string{runner.ENGINE_EVAL, runner.ENGINE_VM} {
+		var out bytes.Buffer
+		repl.Start(strings.NewReader(input), &out, engine)
+
+		expected := ">> >> Traceback (most recent call last):\n" +
+			"  File \"<stdin:2>\", line 1, in <module>\n" +
+			"    f(true)\n" +
+			"    ^\n" +
+			"  File \"<stdin:1>\", line 1, in f\n" +
+			"    func f(x) { return -x }\n" +
+			"                       ^\n" +
+			"TypeError: unknown operator: -BOOLEAN\n" +
+			">> "
+		if out.String() != expected {
+			t.Errorf("wrong output with %s. expected=%q, got=%q", engine, expected, out.String())
+		}
+	}
+}
+
+func TestStartDivisionByZero(t *testing.T) {
+	input := strings.Join([]string{
+		"let a = 10",
+		"a / 0",
+		"a % 2",
+	}, "\n")
+
+	for _, engine := range []string{runner.ENGINE_EVAL, runner.ENGINE_VM} {
+		var out bytes.Buffer
+		repl.Start(strings.NewReader(input), &out, engine)
+
+		expected := ">> >> Traceback (most recent call last):\n" +
+			"  File \"<stdin:2>\", line 1, in <module>\n" +
+			"    a / 0\n" +
+			"      ^\n" +
+			"ZeroDivisionError: division by zero\n" +
+			">> 0\n>> "
+		if out.String() != expected {
+			t.Errorf("wrong output with %s. expected=%q, got=%q", engine, expected, out.String())
+		}
+	}
+}
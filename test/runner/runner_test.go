@@ -0,0 +1,169 @@
+package runner
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"pythia/object"
+	"pythia/runner"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedCode   int
+		expectedErrOut string
+	}{
+		{"let a = 1; a + 2;", runner.EXIT_SUCCESS, ""},
+		{"#!/usr/bin/env pythia\nlet a = 1;", runner.EXIT_SUCCESS, ""},
+		{"let = 1;", runner.EXIT_FAILURE, "expected next token to be IDENT"},
+		{"1 + true;", runner.EXIT_FAILURE, "TypeError: type mismatch: INTEGER + BOOLEAN"},
+		{"1 / 0;", runner.EXIT_FAILURE, "ZeroDivisionError: division by zero"},
+	}
+
+	for _, tt := range tests {
+		var errOut bytes.Buffer
+
+		code := runner.Run(tt.input, object.NewEnvironment(), &errOut)
+		if code != tt.expectedCode {
+			t.Errorf("wrong exit code for %q. expected=%d, got=%d", tt.input, tt.expectedCode, code)
+		}
+		if !strings.Contains(errOut.String(), tt.expectedErrOut) {
+			t.Errorf("wrong error output for %q. expected=%q, got=%q", tt.input, tt.expectedErrOut, errOut.String())
+		}
+	}
+}
+
+func TestRunRecoversPanic(t *testing.T) {
+	var errOut bytes.Buffer
+
+	// evaluating with nil environment panics inside the engine
+	code := runner.Run("let a = 1;", nil, &errOut)
+	if code != runner.EXIT_FAILURE {
+		t.Errorf("wrong exit code. expected=%d, got=%d", runner.EXIT_FAILURE, code)
+	}
+	if !strings.HasPrefix(errOut.String(), "internal error: ") {
+		t.Errorf("wrong error output. got=%q", errOut.String())
+	}
+}
+
+func TestRunFileArgv(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pythia")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "argv.pyt")
+	source := `if (len(argv) != 3) { 1 + true }
+if (argv[2] == "b") { 0 } else { 1 + true }`
+	if err := ioutil.WriteFile(path, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, engine := range []string{runner.ENGINE_EVAL, runner.ENGINE_VM} {
+		var errOut bytes.Buffer
+		code := runner.RunFile(path, []string{"a", "b"}, engine, &errOut)
+		if code != runner.EXIT_SUCCESS {
+			t.Errorf("wrong exit code with %s. expected=%d, got=%d (%s)", engine, runner.EXIT_SUCCESS, code, errOut.String())
+		}
+
+		code = runner.RunFile(filepath.Join(dir, "missing.pyt"), nil, engine, &errOut)
+		if code != runner.EXIT_FAILURE {
+			t.Errorf("wrong exit code for missing file with %s. expected=%d, got=%d", engine, runner.EXIT_FAILURE, code)
+		}
+	}
+
+	var errOut bytes.Buffer
+	code := runner.RunFile(path, nil, "jit", &errOut)
+	if code != runner.EXIT_FAILURE || errOut.String() != "unknown engine: jit\n" {
+		t.Errorf("wrong result for unknown engine. code=%d, output=%q", code, errOut.String())
+	}
+}
+
+func TestRunErrorSnippet(t *testing.T) {
+	input := "let a = 1;\n\tlet b = a + true;"
+
+	var errOut bytes.Buffer
+	runner.Run(input, object.NewEnvironment(), &errOut)
+
+	expected := "Traceback (most recent call last):\n" +
+		"  line 2, in <module>\n" +
+		"    \tlet b = a + true;\n" +
+		"    \t          ^\n" +
+		"TypeError: type mismatch: INTEGER + BOOLEAN\n"
+	if errOut.String() != expected {
+		t.Errorf("wrong error output. expected=%q, got=%q", expected, errOut.String())
+	}
+}
+
+func TestRunErrorTraceback(t *testing.T) {
+	input := `func inner(x) {
+  return x + "a"
+}
+let outer = func() { inner(1) }
+outer()`
+
+	var errOut bytes.Buffer
+	runner.Run(input, object.NewEnvironment(), &errOut)
+
+	expected := "Traceback (most recent call last):\n" +
+		"  line 5, in <module>\n" +
+		"    outer()\n" +
+		"    ^\n" +
+		"  line 4, in <anonymous>\n" +
+		"    let outer = func() { inner(1) }\n" +
+		"                         ^\n" +
+		"  line 2, in inner\n" +
+		"      return x + \"a\"\n" +
+		"               ^\n" +
+		"TypeError: type mismatch: INTEGER + STRING\n"
+	if errOut.String() != expected {
+		t.Errorf("wrong error output. expected=%q, got=%q", expected, errOut.String())
+	}
+}
+
+func TestRunFileImport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pythia")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"main.pyt": "import \"util\" as u\nu.check(1)",
+		"util.pyt": "func check(x) {\n  return x + \"a\"\n}",
+	}
+	for name, source := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(source), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mainPath := filepath.Join(dir, "main.pyt")
+	utilPath := filepath.Join(dir, "util.pyt")
+
+	expected := "Traceback (most recent call last):\n" +
+		"  File \"" + mainPath + "\", line 2, in <module>\n" +
+		"    u.check(1)\n" +
+		"      ^\n" +
+		"  File \"" + utilPath + "\", line 2, in check\n" +
+		"      return x + \"a\"\n" +
+		"               ^\n" +
+		"TypeError: type mismatch: INTEGER + STRING\n"
+
+	for _, engine := range []string{runner.ENGINE_EVAL, runner.ENGINE_VM} {
+		var errOut bytes.Buffer
+		code := runner.RunFile(mainPath, nil, engine, &errOut)
+		if code != runner.EXIT_FAILURE {
+			t.Errorf("wrong exit code with %s. expected=%d, got=%d", engine, runner.EXIT_FAILURE, code)
+		}
+
+		if errOut.String() != expected {
+			t.Errorf("wrong error output with %s. expected=%q, got=%q", engine, expected, errOut.String())
+		}
+	}
+}
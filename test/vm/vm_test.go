@@ -0,0 +1,478 @@
+package vm
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"pythia/ast"
+	"pythia/compiler"
+	"pythia/evaluator"
+	"pythia/lexer"
+	"pythia/object"
+	"pythia/parser"
+	"pythia/vm"
+	"testing"
+)
+
+// TestParity runs the same programs with tree-walking evaluator and vm, and compares the results
+func TestParity(t *testing.T) {
+	tests := []string{
+		// expressions
+		"1 + 2 * 3 - 4 / 2",
+		"7 % 3 + (1 << 4) - (256 >> 2) + (6 & 3) + (6 | 3) + (6 ^ 3)",
+		"1.5 * 2 + 1",
+		"-5 + -(-3)",
+		"!true == false",
+		"1 < 2 && 2 <= 2 && 3 > 2 && 3 >= 4",
+		`"ab" + "cd"`,
+		`"ab" == "ab"`,
+		"[1, 2] == [1, 2]",
+		"null",
+		"0 || 5",
+		"1 && 0",
+		"false || null",
+		"let n = 0; true || (n += 1); n",
+		"3 > 2 ? 10 : 20",
+		"let x = 5; x > 10 ? \"big\" : x > 3 ? \"mid\" : \"small\"",
+		"9223372036854775807 + 1",
+		"-9223372036854775807 - 2 - 1",
+		"3037000500 * 3037000500",
+		"(1 << 64) * (1 << 64) / 3 % 1000000007",
+		"type(1 << 64)",
+		"let n = 9223372036854775807; n += 1; n -= 1; n",
+		"(1 << 64) << -1",
+		`let a = [1, 2, 3]; let result = 0; for x in a { for y in a { result += x * y } }
+result`,
+		`let h = {1: 1, 2: 2}; let result = 0; for k, v in h { for v2 in h { result += k * 10 + v2 } }
+result`,
+		`let a = [1, 2, 3]
+func f(n) {
+	let total = 0
+	for x in a {
+		total += x
+		if (n > 0) { total += f(n - 1) }
+	}
+	return total
+}
+f(2)`,
+		// hash order
+		`{"b": 1, "a": 2, "b": 3, 1: 4}`,
+		`let h = {"b": 1, "a": 2}; delete(h, "b"); h["b"] = 5; h["a"] += 1; h`,
+		`let s = ""; for k, v in {"z": 1, "y": 2, "x": 3} { s += k + string(v) }
+s`,
+		"1 / 0",
+		"1 % 0",
+		"2.5 / 0",
+		"1 >> -1",
+		"let a = 1; a /= 0",
+		"let a = [1]; a[0] %= 0",
+		`try { 1 / 0 } catch (e) { e.kind() }`,
+		// variables and assignment
+		"let a = 1; let b = a + 1; b",
+		"let a = 1; a += 2; a *= 3; a -= 1; a /= 2; a %= 3; a",
+		"let a = 1; a = 2",
+		"let s = \"a\"; s += \"b\"; s",
+		"let arr = [1, 2, 3]; arr[1] = 5; arr[2] += 1; arr",
+		`let h = {"a": 1}; h["b"] = 2; h["a"] += 10; h["a"] + h["b"]`,
+		`let h = {1: "one", true: "yes"}; h[1] + h[true]`,
+		`let h = {"a": 1}; h["x"]`,
+		"let arr = [1, [2, 3]]; arr[1][0]",
+		"let arr = [1, [2, 3]]; arr[1][0] = 5; arr[1][1] += 1; arr",
+		`let h = {"a": {"b": [1]}}; h["a"]["b"][0] -= 3; h`,
+		"let arr = [[1]]; arr[0][1] = 2",
+		"undefinedName[0][1] = 2",
+		"func f() { }\nf()[0] = 1",
+		"func f() { }\nlet arr = [1]; arr[f()] = 1",
+		// scopes
+		"let x = 1; if (true) { let x = 2 }\nx",
+		"if (true) { let y = 2 }\ny",
+		"let x = 1; if (true) { let y = 2; x = y }\nx",
+		"let x = 1; func f() { let x = 2 }\nf(); x",
+		"func f() { let z = 3; return z }\nf()",
+		"let x = 1; if (true) { print(x); let x = 5 }\nx",
+		"let x = null; x",
+		"let x = null; x = 1; x",
+		"let x = null; x += 1",
+		"let x = null; func f() { x = 3; return x }\nf() + x",
+		"func f() { let y = null; return y }\nf()",
+		"func f() { let y = null; y = 2; return y }\nf()",
+		"func f() { let y = null; return func() { y = 4; return y } }\nf()()",
+		// functions
+		"func add(a, b) { return a + b }\nadd(1, 2)",
+		"let add = func(a, b) { a + b }\nadd(1, 2)",
+		"func f() { }\nf()",
+		"func f() { return }\nf()",
+		"func f(a) { return a }\nf(1, 2)",
+		"func f(a, b) { return a }\nf(1)",
+		"func fib(n) { if (n < 2) { return n }\nreturn fib(n - 1) + fib(n - 2) }\nfib(15)",
+		"func counter() { let c = 0; return func() { c += 1; return c } }\nlet next = counter(); next(); next(); next()",
+		"let c = 10; func counter() { let c = 0; return func() { c += 1; return c } }\nlet a = counter(); let b = counter(); a(); a(); [b(), a(), c]",
+		"let x = 1; func f() { let y = x; let x = 2; return [y, x] }\n[f(), x]",
+		"let x = 1; func f() { x = 3; let x = 2; x += 1; return x }\n[f(), x]",
+		"let x = 1; if (true) { let y = x; let x = 5; x += y }\nx",
+		"let x = 1; func f() { func x() { return 5 }\nreturn x() }\nf() + x",
+		"func adder(x) { func add(y) { return x + y }\nreturn add }\nadder(2)(3)",
+		"let fs = []; for i in [1, 2, 3] { fs = append(fs, func() { return i }) }\nfs[0]() + fs[2]()",
+		"func f() { return 1 }\nf",
+		"let f = func(x) { x }\nf",
+		"func f() { return func(a) { a } }\nf()",
+		"5()",
+		// builtins
+		"len([1, 2, 3]) + len(\"abcd\")",
+		"type(1)",
+		"let len = 5; len",
+		"string(12) + \"!\"",
+		"let x = print(1); x",
+		"range(3)",
+		"append([1], 2)",
+		"len(\"한글 😀\")",
+		"let s = \"\"; for i, c in \"한글a\" { s += string(i) + c }\ns",
+		"array(\"한a\")",
+		"bytes(\"aé\")",
+		"runes(\"aé한\")",
+		"let 이름 = \"값\"; 이름",
+		"\"hello\"[-1] + \"한글\"[0]",
+		"[1, 2, 3][-1]",
+		"[1, 2, 3][-4]",
+		"\"abc\"[3]",
+		"[1, 2, 3, 4, 5][1:4:2]",
+		"[1, 2, 3, 4, 5][::-1]",
+		"\"hello\"[1:-1]",
+		"range(0, 10)[-3:]",
+		"let a = [1, 2, 3, 4]; a[1:3] = [7, 8, 9]; a",
+		"func f() { let a = [1, 2, 3, 4]; a[::2] = [0, 0]; a[-1] = 5; return a }\nf()",
+		"let a = [1, 2, 3, 4]; a[::2] = [0]",
+		"let a = [1]; a[:1] += [3]",
+		"[1, 2][::0]",
+		"[1, 2][\"a\":]",
+		"1[1:]",
+		"\"a,b\".split(\",\")",
+		"\"-\".join([\"x\", \"y\"]) + \"  z \".trim().upper()",
+		"\"{} is {}\".format(\"x\".padLeft(3, \"_\"), \"abc\".find(\"c\"))",
+		"func f(s) { return s.replace(\"a\", \"b\").repeat(2) }\nf(\"aa\")",
+		"\"abc\".split(1)",
+		"\"{}\".format()",
+		"\"abc\".reverse()",
+		"let r = 0; if (\"abc\".startsWith(\"x\") || [1].isEmpty() || \"a\" == \"b\") { r = 1 } else { r = 2 }\nr",
+		"let a = [3, 1]; a.push(2); a.insert(0, 9); a.remove(9); let r = [a.pop(), a.indexOf(1), a.contains(3), a]\nr",
+		"let a = [1, 2, 3]; a.reverse().join(\"-\") + [4, 5].slice(-1).join()",
+		"[\"bb\", \"a\", \"ccc\"].sort(func(a, b) { return len(b) - len(a) })",
+		"let n = 10; [1, 2, 3].filter(func(x) { return x > 1 }).map(func(x) { return x + n })",
+		"[1, 2, 3].reduce(func(acc, x) { return acc * x }, 1)",
+		"func big(x) { return x > 1 }\nlet r = [[1, 2].find(big), [1, 2].any(big), [1, 2].all(big), [1, 2].map(string)]\nr",
+		"let sum = 0; [1, 2, 3].forEach(func(x) { sum += x })\nsum",
+		"let a = [2, 1]; let r = 0; try { a.sort(func(a, b) { throw \"x\" }) } catch (e) { r = e.message() }\n[r, a]",
+		"func check(x) { return -x }\n[1, true].map(check)",
+		"[1, 2].map(func(x) { throw \"bad\" })",
+		"[1, \"a\"].sort()",
+		"[].pop()",
+		"[1].map(1)",
+		"let a = [1]; [a.push(2), a.insert(0, 0), a.remove(0), a.forEach(func(x) {}), a.clear()]",
+		"[[].first(), [].last()]",
+		"let a = [1, 2]; a.forEach(func(x) { a.push(x * 10) }); a",
+		"let a = [1, 2, 3]; [a.map(func(x) { a.clear(); return x }), a]",
+		"range(0, 10, 3)",
+		"len(range(10, 0, -3)) + range(10, 0, -3)[2]",
+		"range(0, 3)[5]",
+		"range(0, 3, 0)",
+		"let r = range(-9223372036854775807 - 1, 9223372036854775807); [r[-1], r[len(r) - 1]]",
+		"array(range(0, 1 << 62))",
+		"range(0, 0).last()",
+		"func f() { }\nrange(f(), 3)",
+		"func f() { }\nappend(f(), 1)",
+		"range(0, 10, 2).contains(4)",
+		"range(0, 10, 2).contains(5) ? 1 : 2",
+		"append(range(0, 3), 9)",
+		"array(range(5, 0, -2))",
+		"let s = 0; for i in range(0, 100000000000) { if (i == 5) { break }\ns += i }\ns",
+		"let s = 0; for i, v in range(3, 6) { s += i * v }\ns",
+		"foo",
+		"foo = 1",
+		// loops
+		"let s = 0; for i in [1, 2, 3] { s += i }\ns",
+		"let s = 0; for i, v in [10, 20, 30] { s += i * v }\ns",
+		`let s = ""; for k, v in {"a": 1} { s = k + string(v) }
+s`,
+		`let s = ""; for k in {"a": 1} { s = k }
+s`,
+		"let s = 0; for i in range(0, 10) { if (i % 2 == 0) { continue }\nif (i > 7) { break }\ns += i }\ns",
+		"let s = 0; for let i = 0; i < 10; i += 1 { s += i }\ns",
+		"let i = 0; for i < 5 { i += 1 }\ni",
+		"let i = 0; for { i += 1; if (i == 7) { break } }\ni",
+		"let s = 0; for let i = 0; i < 5; i += 1 { if (i == 2) { continue }\ns += i }\ns",
+		"let s = 0; outer: for i in [1, 2, 3] { for j in [1, 2, 3] { if (j == 2) { continue outer }\nif (i == 3) { break outer }\ns += i * j } }\ns",
+		"func f() { for i in [1, 2, 3] { if (i == 2) { return i * 10 } } }\nf()",
+		"func f() { for i in [1, 2] { for j in [3, 4] { return i + j } } }\nf()",
+		"for x in 5 { }",
+		"for x in range(1) { }",
+		"let total = 0; for let i = 0; i < 3; i += 1 { let sq = i * i; total += sq }\ntotal",
+		// exceptions
+		"let r = 0; try { r = 1 + true } catch (e) { r = e.message() }\nr",
+		"let r = \"\"; try { throw \"boom\" } catch (e) { r = e.kind() + \":\" + e.message() }\nr",
+		"let r = []; try { r = append(r, 1) } finally { r = append(r, 2) }\nr",
+		"let r = []; try { throw 1 } catch (e) { r = append(r, e.message()) } finally { r = append(r, \"f\") }\nr",
+		"func f() { try { return 1 } finally { print(\"cleanup\") } }\nf()",
+		"func f() { try { return 1 } finally { return 2 } }\nf()",
+		"func f() { try { throw \"a\" } catch (e) { return \"caught\" } finally { } }\nf()",
+		"func f() { try { throw \"a\" } finally { return \"finally\" } }\nf()",
+		"let n = 0; for i in [1, 2, 3] { try { if (i == 2) { break } } finally { n += 1 } }\nn",
+		"let n = 0; for i in [1, 2, 3] { try { continue } finally { n += i } }\nn",
+		"func inner() { throw error(\"ValueError\", \"bad\") }\nfunc outer() { inner() }\nlet r = \"\"; try { outer() } catch (e) { r = e.kind() }\nr",
+		"try { throw \"inner\" } catch (e) { throw e }",
+		"try { 1 } catch (e) { }",
+		"try { throw \"a\" } finally { }",
+		"let r = 0; try { try { throw 1 } finally { r += 1 } } catch (e) { r += 10 }\nr",
+		"let r = 0; try { try { throw 1 } catch (e) { throw 2 } } catch (e) { r = e.message() }\nr",
+		"func f(n) { if (n == 0) { throw \"deep\" }\nf(n - 1) }\nlet r = 0; try { f(10) } catch (e) { r = len(e.trace()) }\nr",
+		"let x = 0; try { let x = 5 } catch (e) { }\nx",
+		"let i = 5; for i in [1, 2] {}\ni",
+		"let h = {\"a\": 1, \"b\": 2}; let s = \"\"; for k in h { if (k == \"a\") { delete(h, \"a\"); delete(h, \"b\"); h[\"c\"] = 3 }\ns += k }\ns",
+		"let i = 5; let k = 7; for k, i in {\"a\": 1} {}\n[i, k]",
+		"func f() { let i = 5; for i in [1, 2] {}\nreturn i }\nf()",
+		"throw 5",
+		"let e = error(\"boom\")\nfunc f() { throw e }\nlet r = []; try { f() } catch (x) { r = append(r, len(x.trace())) }\ntry { f() } catch (x) { r = append(r, len(x.trace())) }\nr",
+		"let e = error(\"boom\"); e.message()",
+		"let n = 0; true ? n : n += 1",
+		// generators and user-defined iterables
+		"func count(n) { let i = 0; while i < n { yield i; i += 1 } }\nlet r = []; for v in count(3) { r = append(r, v) }\nr",
+		"func pairs() { yield \"a\"; yield \"b\" }\nlet r = []; for i, v in pairs() { r = append(r, [i, v]) }\nr",
+		"let g = func() { yield 1; yield; return 5 }(); let r = [next(g), next(g), next(g, \"end\")]\nr",
+		"func fib() { let a = 0; let b = 1; for { yield a; let t = a + b; a = b; b = t } }\nlet r = []; for v in fib() { if (v > 20) { break }\nr = append(r, v) }\nr",
+		"func inner() { yield 1; yield 2 }\nfunc outer() { for v in inner() { yield v * 10 }\nyield 30 }\nlet r = []; for v in outer() { r = append(r, v) }\nr",
+		"func g() { try { yield 1; throw \"boom\" } catch (e) { yield e.message() } finally { yield \"finally\" } }\nlet r = []; for v in g() { r = append(r, v) }\nr",
+		"let cleaned = false; func g() { try { yield 1; yield 2 } finally { cleaned = true } }\nfor v in g() { break }\ncleaned",
+		"func g(x) { let y = x * 2; yield func() { return y } }\nlet f = next(g(4)); f()",
+		"func g() { yield print }\nnext(g())",
+		"type(func() { yield 1 }())",
+		"func g() { yield 1 }\ng()",
+		"func counter(n) { let i = 0; return {\"next\": func() { if (i >= n) { throw error(\"StopIteration\", \"\") }\ni += 1; return i }} }\nlet c = {\"__iter__\": func() { return counter(3) }}\nlet r = []; for v in c { r = append(r, v) }\nfor i, v in c { r = append(r, i) }\nr",
+		"let c = {\"__iter__\": func() { yield \"x\"; yield \"y\" }}\nlet r = \"\"; for v in c { r += v }\nr",
+		"let c = {\"__iter__\": func() { return [3, 4] }}\nlet r = 0; for v in c { r += v }\nr",
+		"let g = func() { yield 1 }(); next(g); next(g)",
+		"next([1])",
+		"func g(a) { yield a }\ng()",
+		"func g() { yield 1; throw error(\"ValueError\", \"bad\") }\nfor v in g() {}",
+		"func g() { yield 1; yield -true }\nlet it = g(); next(it); next(it)",
+		"let g = func() { yield next(g) }(); next(g)",
+		"for v in {\"__iter__\": func() { return 1 }} {}",
+		"for v in {\"__iter__\": func() { return {\"next\": func() { return -true }} }} {}",
+		"for v in {\"__iter__\": func() { throw \"no\" }} {}",
+		"func counter(n) { let i = 0; return {\"next\": func() { if (i >= n) { throw error(\"StopIteration\", \"\") }\ni += 1; return i }} }\nlet c = {\"__iter__\": func() { return counter(3) }}\nlet it = iter(c); let r = [next(it), array(it), array(c)]\nr",
+		"array({\"__iter__\": func() { yield \"x\"; yield \"y\" }})",
+		"let it = iter([1, 2]); next(it) + next(it)",
+		"iter(1)",
+		"func start() { throw \"no\" }\nlet c = {\"__iter__\": start}\narray(c)",
+		"array({\"__iter__\": func() { return {\"next\": func() { return -true }} }})",
+		// errors
+		"1 + true",
+		"[1, 2][5]",
+		"{}[[1]]",
+		"-true",
+		"5.foo()",
+		"[1].foo()",
+		"let a = 1; a[0] = 1",
+		".foo",
+	}
+
+	for _, input := range tests {
+		expected := evaluator.Eval(parse(t, input), object.NewEnvironment())
+		result := run(t, input)
+
+		if inspect(result) != inspect(expected) {
+			t.Errorf("wrong result for %q. expected=%s, got=%s", input, inspect(expected), inspect(result))
+			continue
+		}
+
+		expectedErr, ok := expected.(*object.Error)
+		if !ok {
+			continue
+		}
+		err := result.(*object.Error)
+		if err.Pos.Line != expectedErr.Pos.Line || err.Pos.Column != expectedErr.Pos.Column {
+			t.Errorf("wrong error position for %q. expected=%s, got=%s", input, expectedErr.Pos, err.Pos)
+		}
+		if len(err.Stack) != len(expectedErr.Stack) {
+			t.Errorf("wrong error stack for %q. expected=%v, got=%v", input, expectedErr.Stack, err.Stack)
+		}
+	}
+}
+
+func TestErrorTrace(t *testing.T) {
+	input := `func inner(x) {
+  return x + "a"
+}
+let outer = func() {
+  inner(1)
+}
+outer()`
+
+	expected := evaluator.Eval(parse(t, input), object.NewEnvironment()).(*object.Error)
+
+	err, ok := run(t, input).(*object.Error)
+	if !ok {
+		t.Fatalf("result is not error")
+	}
+
+	expectedTrace := expected.Traceback()
+	trace := err.Traceback()
+	if len(trace) != len(expectedTrace) {
+		t.Fatalf("wrong traceback length. expected=%d, got=%d", len(expectedTrace), len(trace))
+	}
+	for i, frame := range trace {
+		if frame.String() != expectedTrace[i].String() || frame.Pos.Column != expectedTrace[i].Pos.Column {
+			t.Errorf("wrong frame %d. expected=%s, got=%s", i, expectedTrace[i], frame)
+		}
+	}
+}
+
+func TestRecursionLimit(t *testing.T) {
+	tests := []string{
+		"func f(n) { return f(n + 1) }\nf(0)",
+		"func f(x) { return [x].map(f) }\nf(1)",
+	}
+
+	for _, input := range tests {
+		expected := evaluator.Eval(parse(t, input), object.NewEnvironment()).(*object.Error)
+		result := run(t, input)
+
+		err, ok := result.(*object.Error)
+		if !ok {
+			t.Fatalf("result is not error for %q. got=%T (%+v)", input, result, result)
+		}
+		if err.Message != "maximum recursion depth exceeded" || err.Message != expected.Message {
+			t.Errorf("wrong error message for %q. expected=%q, got=%q", input, expected.Message, err.Message)
+		}
+		if len(err.Stack) != len(expected.Stack) {
+			t.Errorf("wrong error stack length for %q. expected=%d, got=%d", input, len(expected.Stack), len(err.Stack))
+		}
+	}
+
+	result := run(t, "func f(n) { return f(n + 1) }\nf(0)").(*object.Error)
+	if len(result.Stack) != vm.MAX_FRAMES-1 {
+		t.Errorf("wrong error stack length. expected=%d, got=%d", vm.MAX_FRAMES-1, len(result.Stack))
+	}
+}
+
+// TestGlobals runs inputs like REPL, which share compiler and globals
+func TestGlobals(t *testing.T) {
+	c := compiler.New()
+	globals := vm.NewGlobals()
+
+	globals.Set(c.DefineGlobal("argv"), &object.Integer{Value: 10})
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let a = argv + 1", "<nil>"},
+		{"func double(x) { return x * 2 }", "<nil>"},
+		{"double(a)", "22"},
+		{"a = 5", "<nil>"},
+		{"double(a) + argv", "20"},
+		{"let n = null", "<nil>"},
+		{"n", "null"},
+		{"n = 7", "<nil>"},
+		{"n", "7"},
+	}
+
+	for _, tt := range tests {
+		bytecode, err := c.Compile(parse(t, tt.input))
+		if err != nil {
+			t.Fatalf("compile error for %q: %s", tt.input, err)
+		}
+
+		result := vm.NewWithGlobals(bytecode, globals).Run()
+		if inspect(result) != tt.expected {
+			t.Errorf("wrong result for %q. expected=%s, got=%s", tt.input, tt.expected, inspect(result))
+		}
+	}
+}
+
+func parse(t *testing.T, input string) *ast.Program {
+	t.Helper()
+
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parse errors for %q: %v", input, p.Errors())
+	}
+
+	return program
+}
+
+func run(t *testing.T, input string) object.Object {
+	t.Helper()
+
+	bytecode, err := compiler.New().Compile(parse(t, input))
+	if err != nil {
+		t.Fatalf("compile error for %q: %s", input, err)
+	}
+
+	return vm.New(bytecode).Run()
+}
+
+func inspect(obj object.Object) string {
+	if obj == nil {
+		return "<nil>"
+	}
+
+	return obj.Inspect()
+}
+
+func TestImportModule(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pythia")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"util.pyt":     "let base = 10\nfunc add(a, b) { return a + b + base }",
+		"raise.pyt":    "let x = 1\nlet y = x + true",
+		"callback.pyt": "func apply(f, x) { return f(x) }\nfunc sum(it) { let s = 0; for v in it { s += v }\nreturn s }\nfunc each(xs, f) { return xs.map(f) }",
+	}
+	for name, source := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(source), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	evaluator.SetSearchPaths(dir)
+	defer evaluator.SetSearchPaths()
+
+	tests := []string{
+		`import "util"; util.add(1, 2)`,
+		`import "util" as u; u.base`,
+		`from "util" import add, base; add(base, 1)`,
+		"func f() { import \"util\"; return util.base }\nf()",
+		`import "util"; let u = util; u`,
+		`from "util" import missing`,
+		`import "util"; util.missing`,
+		`import "missing"`,
+		`import "raise"`,
+		// functions of module call functions of the importer
+		`import "callback"; callback.apply(func(x) { return x * 2 }, 5)`,
+		`from "callback" import apply; let n = 3; apply(func(x) { n += x; return n }, 4); n`,
+		`import "callback"; callback.sum({"__iter__": func() { yield 1; yield 2 }})`,
+		`import "callback"; callback.each([1, 2], func(x) { return x + 1 })`,
+		`import "callback"; callback.apply(func(x) { yield x }, 7)`,
+		`import "callback"; callback.apply(func(x) { return x + true }, 1)`,
+		`import "callback"; callback.each([1], func(x) { throw "bad" })`,
+	}
+
+	for _, input := range tests {
+		expected := evaluator.Eval(parse(t, input), object.NewEnvironment())
+		result := run(t, input)
+
+		if inspect(result) != inspect(expected) {
+			t.Errorf("wrong result for %q. expected=%s, got=%s", input, inspect(expected), inspect(result))
+			continue
+		}
+
+		if expectedErr, ok := expected.(*object.Error); ok {
+			if err := result.(*object.Error); len(err.Stack) != len(expectedErr.Stack) {
+				t.Errorf("wrong error stack for %q. expected=%v, got=%v", input, expectedErr.Stack, err.Stack)
+			}
+		}
+	}
+}
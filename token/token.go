@@ -1,5 +1,7 @@
 package token
 
+import "fmt"
+
 const (
 	ILLEGAL = "ILLEGAL"
 	EOF     = "EOF"
@@ -44,6 +46,7 @@ const (
 	COMMA     = ","
 	SEMICOLON = ";"
 	COLON     = ":"
+	QUESTION  = "?"
 
 	LPAREN   = "("
 	RPAREN   = ")"
@@ -59,9 +62,20 @@ const (
 	IF       = "IF"
 	ELSE     = "ELSE"
 	RETURN   = "RETURN"
+	YIELD    = "YIELD"
 	NULL     = "NULL"
 	FOR      = "FOR"
 	IN       = "IN"
+	WHILE    = "WHILE"
+	BREAK    = "BREAK"
+	CONTINUE = "CONTINUE"
+	TRY      = "TRY"
+	CATCH    = "CATCH"
+	FINALLY  = "FINALLY"
+	THROW    = "THROW"
+	IMPORT   = "IMPORT"
+	FROM     = "FROM"
+	AS       = "AS"
 )
 
 type TokenType string
@@ -69,19 +83,49 @@ type TokenType string
 type Token struct {
 	Type    TokenType
 	Literal string
+	Pos     Position // where the token starts
+}
+
+// Position is a location in source. Line and Column are 1-based, Offset is 0-based byte offset.
+type Position struct {
+	Filename string
+	Offset   int
+	Line     int
+	Column   int
+}
+
+func (p Position) IsValid() bool { return p.Line > 0 }
+
+func (p Position) String() string {
+	if p.Filename != "" {
+		return fmt.Sprintf("%s, line %d, column %d", p.Filename, p.Line, p.Column)
+	}
+
+	return fmt.Sprintf("line %d, column %d", p.Line, p.Column)
 }
 
 var keywords = map[string]TokenType{
-	"func":   FUNCTION,
-	"let":    LET,
-	"true":   TRUE,
-	"false":  FALSE,
-	"if":     IF,
-	"else":   ELSE,
-	"return": RETURN,
-	"null":   NULL,
-	"for":    FOR,
-	"in":     IN,
+	"func":     FUNCTION,
+	"let":      LET,
+	"true":     TRUE,
+	"false":    FALSE,
+	"if":       IF,
+	"else":     ELSE,
+	"return":   RETURN,
+	"yield":    YIELD,
+	"null":     NULL,
+	"for":      FOR,
+	"in":       IN,
+	"while":    WHILE,
+	"break":    BREAK,
+	"continue": CONTINUE,
+	"try":      TRY,
+	"catch":    CATCH,
+	"finally":  FINALLY,
+	"throw":    THROW,
+	"import":   IMPORT,
+	"from":     FROM,
+	"as":       AS,
 }
 
 func LookupIdent(ident string) TokenType {
@@ -0,0 +1,107 @@
+package vm
+
+import (
+	"pythia/compiler"
+	"pythia/object"
+)
+
+// undefined is the value of variable which is declared but not set yet. It differs from nil, the result of void function,
+// and from object.NULL: pointers to zero-size structs may be equal, so its type has a field.
+var undefined = &undefinedValue{name: "undefined"}
+
+type undefinedValue struct {
+	name string
+}
+
+func (u *undefinedValue) Type() object.ObjectType { return object.NULL_OBJ }
+func (u *undefinedValue) Inspect() string         { return u.name }
+func (u *undefinedValue) Equals(o object.Object) bool {
+	return u == o
+}
+
+// Scope is the local slots of function call or block, like object.Environment
+type Scope struct {
+	slots []object.Object
+	outer *Scope
+}
+
+func NewScope(size int, outer *Scope) *Scope {
+	slots := make([]object.Object, size)
+	for i := range slots {
+		slots[i] = undefined
+	}
+
+	return &Scope{slots: slots, outer: outer}
+}
+
+// outerAt returns the scope of depth, the current scope is depth 0
+func (s *Scope) outerAt(depth int) *Scope {
+	scope := s
+	for ; depth > 0; depth-- {
+		scope = scope.outer
+	}
+
+	return scope
+}
+
+// Closure is a compiled function with the scope where it is defined
+type Closure struct {
+	Fn    *compiler.CompiledFunction
+	Scope *Scope
+
+	vm *VM // VM which creates the closure, its constants and globals run the closure when evaluator calls it
+}
+
+func (cl *Closure) Type() object.ObjectType { return object.FUNCTION_OBJ }
+func (cl *Closure) Inspect() string         { return cl.Fn.Inspect() }
+func (cl *Closure) Equals(o object.Object) bool {
+	obj, ok := o.(*Closure)
+	if !ok {
+		return false
+	}
+
+	return cl == obj
+}
+
+// Invoke runs the closure for tree-walking evaluator, e.g. when function of imported module calls it
+func (cl *Closure) Invoke(args ...object.Object) object.Object {
+	return cl.vm.callClosure(cl, args)
+}
+
+func (cl *Closure) Name() string {
+	if cl.Fn.Name == "" {
+		return object.ANONYMOUS_FRAME
+	}
+
+	return cl.Fn.Name
+}
+
+// Frame is a function call
+type Frame struct {
+	cl     *Closure
+	ip     int // offset of the next instruction
+	bp     int // stack pointer before the function is pushed
+	scope  *Scope
+	callIP int // offset of call instruction in the caller
+}
+
+// Globals are the global variables, they are kept between runs in REPL
+type Globals struct {
+	store []object.Object
+}
+
+func NewGlobals() *Globals {
+	return &Globals{store: []object.Object{}}
+}
+
+// Set sets the global of index, compiler.Compiler.DefineGlobal returns the index of name
+func (g *Globals) Set(index int, val object.Object) {
+	g.grow(index + 1)
+	g.store[index] = val
+}
+
+func (g *Globals) grow(size int) {
+	for len(g.store) < size {
+		g.store = append(g.store, undefined)
+	}
+}
@@ -0,0 +1,100 @@
+package vm
+
+import (
+	"pythia/evaluator"
+	"pythia/object"
+	"pythia/token"
+)
+
+// GENERATOR_STACK_SIZE is the initial stack size of VM which runs a generator or a call from operations
+const GENERATOR_STACK_SIZE = 32
+
+// call calls function without recording it in the stack of error, for operations which call functions,
+// e.g. `__iter__` and `next` of user-defined iterable
+func (vm *VM) call(fn object.Object, args []object.Object) object.Object {
+	switch fn := fn.(type) {
+	case *Closure:
+		return vm.callClosure(fn, args)
+	case *object.Builtin:
+		return fn.Fn(&invoker{vm: vm}, args...)
+	default:
+		return evaluator.ApplyFunction(fn, args)
+	}
+}
+
+// invoker calls function passed to method or builtin, error raised in the function records it with the position of the call
+type invoker struct {
+	vm  *VM
+	pos token.Position
+}
+
+func (inv *invoker) Invoke(fn object.Object, args ...object.Object) object.Object {
+	switch fn := fn.(type) {
+	case *Closure:
+		// the error is raised at the call site, without the closure which is not called
+		if err := inv.vm.checkDepth(); err != nil {
+			return err
+		}
+		result := inv.vm.callClosure(fn, args)
+		if err, ok := result.(*object.Error); ok {
+			err.Stack = append(err.Stack, object.Frame{Function: fn.Name(), Pos: inv.pos})
+		}
+		return result
+	case *object.Builtin:
+		return fn.Fn(inv, args...)
+	default:
+		return evaluator.CallFunction(fn, args, inv.pos)
+	}
+}
+
+// checkDepth returns error if one more call exceeds the limit of recursion
+func (vm *VM) checkDepth() *object.Error {
+	if vm.depth+len(vm.frames) >= MAX_FRAMES {
+		return evaluator.NewError(object.ERROR, "maximum recursion depth exceeded")
+	}
+
+	return nil
+}
+
+// callClosure runs closure on a new VM which shares globals, and returns the result.
+// Generator function returns a generator whose VM runs until yield on every step.
+func (vm *VM) callClosure(cl *Closure, args []object.Object) object.Object {
+	if len(args) < cl.Fn.NumParameters {
+		return evaluator.NewError(object.TYPE_ERROR, "wrong number of arguments. got=%d, want=%d", len(args), cl.Fn.NumParameters)
+	}
+
+	scope := NewScope(cl.Fn.NumLocals, cl.Scope)
+	copy(scope.slots, args[:cl.Fn.NumParameters])
+
+	if err := vm.checkDepth(); err != nil {
+		return err
+	}
+
+	sub := &VM{
+		constants: vm.constants,
+		globals:   vm.globals,
+		stack:     make([]object.Object, GENERATOR_STACK_SIZE),
+		frames:    []*Frame{{cl: cl, scope: scope}},
+		depth:     vm.depth + len(vm.frames),
+	}
+
+	if !cl.Fn.IsGenerator {
+		return sub.Run()
+	}
+
+	return &object.Generator{Name: cl.Name(), Step: func() (object.Object, bool) {
+		sub.yielded = false
+		result := sub.Run()
+		if sub.yielded {
+			if result == nil { // yield of void function call
+				result = evaluator.NULL
+			}
+			return result, true
+		}
+		if _, ok := result.(*object.Error); ok {
+			return result, true
+		}
+
+		return nil, false
+	}}
+}
@@ -0,0 +1,84 @@
+package vm
+
+import (
+	"pythia/compiler"
+	"pythia/evaluator"
+	"pythia/object"
+	"pythia/token"
+)
+
+// binaryOperation computes integer addition, subtraction and comparison directly, and the others with evaluator
+func binaryOperation(op compiler.Opcode, left, right object.Object) object.Object {
+	l, ok := left.(*object.Integer)
+	if !ok {
+		return evaluator.InfixOperation(operators[op], left, right)
+	}
+	r, ok := right.(*object.Integer)
+	if !ok {
+		return evaluator.InfixOperation(operators[op], left, right)
+	}
+
+	// overflow is promoted to BigInt by evaluator
+	switch op {
+	case compiler.OpAdd:
+		if res := l.Value + r.Value; (l.Value^res)&(r.Value^res) >= 0 {
+			return &object.Integer{Value: res}
+		}
+	case compiler.OpSub:
+		if res := l.Value - r.Value; (l.Value^r.Value)&(l.Value^res) >= 0 {
+			return &object.Integer{Value: res}
+		}
+	case compiler.OpLess:
+		return evaluator.NativeBoolToBooleanObject(l.Value < r.Value)
+	case compiler.OpGreater:
+		return evaluator.NativeBoolToBooleanObject(l.Value > r.Value)
+	case compiler.OpLessEqual:
+		return evaluator.NativeBoolToBooleanObject(l.Value <= r.Value)
+	case compiler.OpGreaterEqual:
+		return evaluator.NativeBoolToBooleanObject(l.Value >= r.Value)
+	case compiler.OpEqual:
+		return evaluator.NativeBoolToBooleanObject(l.Value == r.Value)
+	case compiler.OpNotEqual:
+		return evaluator.NativeBoolToBooleanObject(l.Value != r.Value)
+	}
+
+	return evaluator.InfixOperation(operators[op], left, right)
+}
+
+// attribute returns the top-level binding of module
+func attribute(obj object.Object, name string) object.Object {
+	mod, ok := obj.(*object.Module)
+	if !ok {
+		return evaluator.NewError(object.ATTRIBUTE_ERROR, "%s has no attribute %s", obj.Type(), name)
+	}
+
+	val, ok := mod.Get(name)
+	if !ok {
+		return evaluator.NewError(object.ATTRIBUTE_ERROR, "module %s has no attribute %s", mod.Name, name)
+	}
+
+	return val
+}
+
+// callMethod calls function of module, or method of object. pos is the position of method name
+func (vm *VM) callMethod(obj object.Object, name string, args []object.Object, pos token.Position) object.Object {
+	if mod, ok := obj.(*object.Module); ok {
+		fn, ok := mod.Get(name)
+		if !ok {
+			return evaluator.NewError(object.ATTRIBUTE_ERROR, "module %s has no attribute %s", mod.Name, name)
+		}
+		return (&invoker{vm: vm, pos: pos}).Invoke(fn, args...)
+	}
+
+	callable, ok := obj.(object.Callable)
+	if !ok {
+		return evaluator.NewError(object.TYPE_ERROR, "%s is not callable object", obj.Type())
+	}
+
+	result, ok := callable.Apply(name, &invoker{vm: vm, pos: pos}, args...)
+	if !ok {
+		return evaluator.NewError(object.ATTRIBUTE_ERROR, "%s is unknown method, %s", name, obj.Type())
+	}
+
+	return result
+}
@@ -0,0 +1,518 @@
+package vm
+
+import (
+	"pythia/compiler"
+	"pythia/evaluator"
+	"pythia/object"
+)
+
+const (
+	STACK_SIZE = 1024                     // initial size of stack, it grows on demand
+	MAX_FRAMES = evaluator.MAX_CALL_DEPTH // limit of recursion
+)
+
+// operators are the operators of binary and prefix opcodes, for evaluator operations
+var operators [256]string
+
+func init() {
+	for op := 0; op < len(operators); op++ {
+		operators[op] = compiler.OperatorOf(compiler.Opcode(op))
+	}
+}
+
+// handler is an active try statement
+type handler struct {
+	frame   int // index of frame which runs try statement
+	catchIP int
+	sp      int
+	scope   *Scope
+}
+
+// VM runs compiled bytecode. It behaves the same as tree-walking evaluator,
+// and shares operations, builtins and modules with it.
+type VM struct {
+	constants []object.Object
+	globals   *Globals
+
+	stack []object.Object
+	sp    int // stack[sp-1] is the top of stack
+
+	frames   []*Frame
+	handlers []handler
+	depth    int // frames of VMs which wait for this VM to return, for the limit of recursion
+
+	lastPopped object.Object
+	yielded    bool // Run stopped at yield of generator
+}
+
+func New(bytecode *compiler.Bytecode) *VM {
+	return NewWithGlobals(bytecode, NewGlobals())
+}
+
+// NewWithGlobals creates VM which shares globals with the previous runs, for REPL
+func NewWithGlobals(bytecode *compiler.Bytecode, globals *Globals) *VM {
+	globals.grow(bytecode.NumGlobals)
+
+	main := &Frame{cl: &Closure{Fn: bytecode.Main}}
+
+	return &VM{
+		constants: bytecode.Constants,
+		globals:   globals,
+		stack:     make([]object.Object, STACK_SIZE),
+		frames:    []*Frame{main},
+	}
+}
+
+// Run runs program, and returns the value of the last statement or the uncaught error.
+// VM of generator resumes from where it yielded last time.
+func (vm *VM) Run() object.Object {
+	frame := vm.frames[len(vm.frames)-1]
+	ins := frame.cl.Fn.Instructions
+
+	for frame.ip < len(ins) {
+		ip := frame.ip
+		op := compiler.Opcode(ins[ip])
+
+		var err *object.Error
+
+		switch op {
+		case compiler.OpConstant:
+			frame.ip = ip + 3
+			vm.push(vm.constants[compiler.ReadUint16(ins[ip+1:])])
+		case compiler.OpNil:
+			frame.ip = ip + 1
+			vm.push(nil)
+		case compiler.OpNull:
+			frame.ip = ip + 1
+			vm.push(evaluator.NULL)
+		case compiler.OpTrue:
+			frame.ip = ip + 1
+			vm.push(evaluator.TRUE)
+		case compiler.OpFalse:
+			frame.ip = ip + 1
+			vm.push(evaluator.FALSE)
+		case compiler.OpPop:
+			frame.ip = ip + 1
+			vm.lastPopped = vm.pop()
+
+		case compiler.OpAdd, compiler.OpSub, compiler.OpMul, compiler.OpDiv, compiler.OpMod,
+			compiler.OpBitAnd, compiler.OpBitOr, compiler.OpBitXor, compiler.OpShiftLeft, compiler.OpShiftRight,
+			compiler.OpEqual, compiler.OpNotEqual, compiler.OpLess, compiler.OpGreater, compiler.OpLessEqual, compiler.OpGreaterEqual:
+			frame.ip = ip + 1
+			right := vm.pop()
+			left := vm.pop()
+			err = vm.pushResult(binaryOperation(op, left, right))
+		case compiler.OpMinus:
+			frame.ip = ip + 1
+			err = vm.pushResult(evaluator.PrefixOperation("-", vm.pop()))
+		case compiler.OpBang:
+			frame.ip = ip + 1
+			vm.push(evaluator.NativeBoolToBooleanObject(!evaluator.IsTruthy(vm.pop())))
+
+		case compiler.OpJump:
+			frame.ip = int(compiler.ReadUint16(ins[ip+1:]))
+		case compiler.OpJumpIfFalse:
+			frame.ip = ip + 3
+			if !evaluator.IsTruthy(vm.pop()) {
+				frame.ip = int(compiler.ReadUint16(ins[ip+1:]))
+			}
+		case compiler.OpJumpIfFalseOrPop:
+			frame.ip = ip + 3
+			if !evaluator.IsTruthy(vm.stack[vm.sp-1]) {
+				frame.ip = int(compiler.ReadUint16(ins[ip+1:]))
+			} else {
+				vm.sp--
+			}
+		case compiler.OpJumpIfTrueOrPop:
+			frame.ip = ip + 3
+			if evaluator.IsTruthy(vm.stack[vm.sp-1]) {
+				frame.ip = int(compiler.ReadUint16(ins[ip+1:]))
+			} else {
+				vm.sp--
+			}
+
+		case compiler.OpGetGlobal:
+			frame.ip = ip + 3
+			val := vm.globals.store[compiler.ReadUint16(ins[ip+1:])]
+			err = vm.pushVariable(val, frame.cl.Fn.Names[ip])
+		case compiler.OpSetGlobal:
+			frame.ip = ip + 3
+			vm.globals.store[compiler.ReadUint16(ins[ip+1:])] = vm.pop()
+		case compiler.OpAssignGlobal:
+			frame.ip = ip + 4
+			slots := vm.globals.store
+			err = vm.assign(slots, int(compiler.ReadUint16(ins[ip+1:])), int(ins[ip+3]), frame.cl.Fn.Names[ip])
+		case compiler.OpGetLocal:
+			frame.ip = ip + 4
+			scope := frame.scope.outerAt(int(ins[ip+1]))
+			val := scope.slots[compiler.ReadUint16(ins[ip+2:])]
+			err = vm.pushVariable(val, frame.cl.Fn.Names[ip])
+		case compiler.OpSetLocal:
+			frame.ip = ip + 4
+			scope := frame.scope.outerAt(int(ins[ip+1]))
+			scope.slots[compiler.ReadUint16(ins[ip+2:])] = vm.pop()
+		case compiler.OpAssignLocal:
+			frame.ip = ip + 5
+			scope := frame.scope.outerAt(int(ins[ip+1]))
+			err = vm.assign(scope.slots, int(compiler.ReadUint16(ins[ip+2:])), int(ins[ip+4]), frame.cl.Fn.Names[ip])
+		case compiler.OpPushScope:
+			frame.ip = ip + 3
+			frame.scope = NewScope(int(compiler.ReadUint16(ins[ip+1:])), frame.scope)
+		case compiler.OpPopScope:
+			frame.ip = ip + 1
+			frame.scope = frame.scope.outer
+
+		case compiler.OpArray:
+			frame.ip = ip + 3
+			n := int(compiler.ReadUint16(ins[ip+1:]))
+			elements := make([]object.Object, n)
+			copy(elements, vm.stack[vm.sp-n:vm.sp])
+			vm.sp -= n
+			vm.push(&object.Array{Elements: elements})
+		case compiler.OpHash:
+			frame.ip = ip + 3
+			n := int(compiler.ReadUint16(ins[ip+1:]))
+			hash := vm.buildHash(vm.stack[vm.sp-n : vm.sp])
+			vm.sp -= n
+			err = vm.pushResult(hash)
+		case compiler.OpIndex:
+			frame.ip = ip + 1
+			index := vm.pop()
+			left := vm.pop()
+			err = vm.pushResult(evaluator.IndexOperation(left, index))
+		case compiler.OpSetIndex:
+			frame.ip = ip + 2
+			container := vm.pop()
+			value := vm.pop()
+			index := vm.pop()
+			operator := compiler.ASSIGN_OPERATORS[ins[ip+1]]
+			if res := evaluator.AssignIndex(operator, frame.cl.Fn.Names[ip], container, index, value); res != nil {
+				err = res.(*object.Error)
+			}
+		case compiler.OpSlice:
+			frame.ip = ip + 1
+			step := vm.pop()
+			end := vm.pop()
+			start := vm.pop()
+			left := vm.pop()
+			err = vm.pushResult(evaluator.SliceOperation(left, start, end, step))
+		case compiler.OpSetSlice:
+			frame.ip = ip + 2
+			container := vm.pop()
+			value := vm.pop()
+			step := vm.pop()
+			end := vm.pop()
+			start := vm.pop()
+			operator := compiler.ASSIGN_OPERATORS[ins[ip+1]]
+			if res := evaluator.AssignSlice(operator, frame.cl.Fn.Names[ip], container, start, end, step, value); res != nil {
+				err = res.(*object.Error)
+			}
+		case compiler.OpAttribute:
+			frame.ip = ip + 3
+			name := vm.constantString(ins[ip+1:])
+			err = vm.pushResult(attribute(vm.pop(), name))
+
+		case compiler.OpClosure:
+			frame.ip = ip + 3
+			fn := vm.constants[compiler.ReadUint16(ins[ip+1:])].(*compiler.CompiledFunction)
+			vm.push(&Closure{Fn: fn, Scope: frame.scope, vm: vm})
+		case compiler.OpCall:
+			frame.ip = ip + 2
+			numArgs := int(ins[ip+1])
+			fn := vm.stack[vm.sp-1-numArgs]
+
+			if cl, ok := fn.(*Closure); ok && cl.Fn.IsGenerator {
+				args := vm.popArgs(numArgs)
+				vm.sp-- // function
+				if err = vm.pushResult(vm.callClosure(cl, args)); err != nil {
+					err.Stack = append(err.Stack, object.Frame{Function: cl.Name(), Pos: frame.cl.Fn.CallSites[ip]})
+				}
+				break
+			}
+			if cl, ok := fn.(*Closure); ok {
+				if err = vm.pushFrame(cl, numArgs, ip); err == nil {
+					frame = vm.frames[len(vm.frames)-1]
+					ins = frame.cl.Fn.Instructions
+				}
+				break
+			}
+
+			args := vm.popArgs(numArgs)
+			vm.sp-- // function
+			err = vm.pushResult((&invoker{vm: vm, pos: frame.cl.Fn.CallSites[ip]}).Invoke(fn, args...))
+		case compiler.OpMethodCall:
+			frame.ip = ip + 4
+			name := vm.constantString(ins[ip+1:])
+			args := vm.popArgs(int(ins[ip+3]))
+			obj := vm.pop()
+			err = vm.pushResult(vm.callMethod(obj, name, args, frame.cl.Fn.CallSites[ip]))
+		case compiler.OpReturn:
+			result := vm.pop()
+
+			// return in top-level stops program
+			if len(vm.frames) == 1 {
+				vm.lastPopped = result
+				return result
+			}
+
+			vm.frames = vm.frames[:len(vm.frames)-1]
+			vm.sp = frame.bp
+			vm.push(result)
+
+			frame = vm.frames[len(vm.frames)-1]
+			ins = frame.cl.Fn.Instructions
+		case compiler.OpYield:
+			frame.ip = ip + 1
+			vm.yielded = true
+			return vm.pop()
+
+		case compiler.OpIterInit:
+			frame.ip = ip + 1
+			err = vm.pushResult(evaluator.GetIterator(vm.pop(), vm.call))
+		case compiler.OpIterNext:
+			frame.ip = ip + 4
+			iter := vm.stack[vm.sp-1].(object.Iterator)
+			required, optional, ok := iter.Next()
+			if !ok {
+				frame.ip = int(compiler.ReadUint16(ins[ip+1:]))
+				break
+			}
+			if e, isError := required.(*object.Error); isError {
+				err = e
+				break
+			}
+
+			hasIndex := ins[ip+3] == 1
+			// index of hash is its key
+			if _, isHash := iter.(*object.HashIterator); hasIndex && isHash {
+				required, optional = optional, required
+			}
+
+			vm.push(required)
+			if hasIndex {
+				vm.push(optional)
+			}
+
+		case compiler.OpTry:
+			frame.ip = ip + 3
+			vm.handlers = append(vm.handlers, handler{
+				frame:   len(vm.frames) - 1,
+				catchIP: int(compiler.ReadUint16(ins[ip+1:])),
+				sp:      vm.sp,
+				scope:   frame.scope,
+			})
+		case compiler.OpPopTry:
+			frame.ip = ip + 1
+			vm.handlers = vm.handlers[:len(vm.handlers)-1]
+		case compiler.OpThrow:
+			frame.ip = ip + 1
+			err = evaluator.Throw(vm.pop())
+		case compiler.OpRethrow:
+			frame.ip = ip + 1
+			err = vm.pop().(*object.Exception).Err
+		case compiler.OpRaise:
+			frame.ip = ip + 3
+			raised := *vm.constants[compiler.ReadUint16(ins[ip+1:])].(*object.Error)
+			err = &raised
+
+		case compiler.OpImport:
+			frame.ip = ip + 3
+			pos := frame.cl.Fn.Position(ip)
+			module := evaluator.ImportModule(vm.constantString(ins[ip+1:]), pos.Filename)
+			if e, ok := module.(*object.Error); ok {
+				// error raised while evaluating module records where the module is imported
+				if e.Pos.IsValid() {
+					e.Stack = append(e.Stack, object.Frame{Function: object.MODULE_FRAME, Pos: pos})
+				}
+				err = e
+				break
+			}
+			vm.push(module)
+		case compiler.OpImportName:
+			frame.ip = ip + 3
+			name := vm.constantString(ins[ip+1:])
+			mod := vm.stack[vm.sp-1].(*object.Module)
+			val, ok := mod.Get(name)
+			if !ok {
+				err = evaluator.NewError(object.IMPORT_ERROR, "cannot import name %s from %s", name, mod.Name)
+				break
+			}
+			vm.push(val)
+		case compiler.OpInstruction:
+			frame.ip = ip + 3
+			err = vm.pushResult(evaluator.RunInstruction(vm.constantString(ins[ip+1:])))
+
+		default:
+			def, _ := compiler.Lookup(byte(op))
+			err = evaluator.NewError(object.ERROR, "unknown opcode %v", def)
+		}
+
+		if err != nil {
+			var ok bool
+			if frame, ok = vm.raise(err, ip); !ok {
+				return err
+			}
+			ins = frame.cl.Fn.Instructions
+		}
+	}
+
+	return vm.lastPopped
+}
+
+func (vm *VM) push(obj object.Object) {
+	if vm.sp == len(vm.stack) {
+		vm.stack = append(vm.stack, obj)
+		vm.stack = vm.stack[:cap(vm.stack)]
+	} else {
+		vm.stack[vm.sp] = obj
+	}
+	vm.sp++
+}
+
+func (vm *VM) pop() object.Object {
+	vm.sp--
+	return vm.stack[vm.sp]
+}
+
+// pushResult pushes the result of operation, or returns it if it is an error
+func (vm *VM) pushResult(obj object.Object) *object.Error {
+	if err, ok := obj.(*object.Error); ok {
+		return err
+	}
+
+	vm.push(obj)
+	return nil
+}
+
+func (vm *VM) popArgs(n int) []object.Object {
+	args := make([]object.Object, n)
+	copy(args, vm.stack[vm.sp-n:vm.sp])
+	vm.sp -= n
+
+	return args
+}
+
+func (vm *VM) constantString(operand []byte) string {
+	return vm.constants[compiler.ReadUint16(operand)].(*object.String).Value
+}
+
+// pushVariable pushes the value of variable. Variable which is not set yet falls back to builtin function
+func (vm *VM) pushVariable(val object.Object, name string) *object.Error {
+	if val != undefined {
+		vm.push(val)
+		return nil
+	}
+
+	if builtin, ok := evaluator.LookupBuiltin(name); ok {
+		vm.push(builtin)
+		return nil
+	}
+
+	return evaluator.NewError(object.NAME_ERROR, "identifier not found: "+name)
+}
+
+// assign updates the slot with the value on the stack and assignment operator
+func (vm *VM) assign(slots []object.Object, index int, operator int, name string) *object.Error {
+	value := vm.pop()
+
+	curr := slots[index]
+	if curr == undefined {
+		return evaluator.NewError(object.NAME_ERROR, "%s is not defined identifier", name)
+	}
+
+	var res object.Object
+	switch operator {
+	case 0: // =
+		res = value
+	case 1: // +=
+		res = binaryOperation(compiler.OpAdd, curr, value)
+		if _, ok := res.(*object.Error); ok {
+			res = evaluator.AssignOperation("+=", curr, value)
+		}
+	default:
+		res = evaluator.AssignOperation(compiler.ASSIGN_OPERATORS[operator], curr, value)
+	}
+
+	if err, ok := res.(*object.Error); ok {
+		return err
+	}
+	slots[index] = res
+
+	return nil
+}
+
+func (vm *VM) buildHash(items []object.Object) object.Object {
+	hash := object.NewHash()
+
+	for i := 0; i < len(items); i += 2 {
+		key, value := items[i], items[i+1]
+
+		hashKey, ok := key.(object.Hashable)
+		if !ok {
+			return evaluator.NewError(object.TYPE_ERROR, "unusable as hash key: %s", key.Type())
+		}
+
+		hash.Set(hashKey.HashKey(), object.HashPair{Key: key, Value: value})
+	}
+
+	return hash
+}
+
+// pushFrame calls closure with arguments on the stack. Extra arguments are ignored like tree-walking evaluator.
+func (vm *VM) pushFrame(cl *Closure, numArgs int, callIP int) *object.Error {
+	if numArgs < cl.Fn.NumParameters {
+		err := evaluator.NewError(object.TYPE_ERROR, "wrong number of arguments. got=%d, want=%d", numArgs, cl.Fn.NumParameters)
+		caller := vm.frames[len(vm.frames)-1]
+		err.Stack = append(err.Stack, object.Frame{Function: cl.Name(), Pos: caller.cl.Fn.CallSites[callIP]})
+		return err
+	}
+
+	if err := vm.checkDepth(); err != nil {
+		return err
+	}
+
+	scope := NewScope(cl.Fn.NumLocals, cl.Scope)
+	copy(scope.slots, vm.stack[vm.sp-numArgs:vm.sp-numArgs+cl.Fn.NumParameters])
+
+	bp := vm.sp - numArgs - 1
+	vm.sp = bp
+	vm.frames = append(vm.frames, &Frame{cl: cl, bp: bp, scope: scope, callIP: callIP})
+
+	return nil
+}
+
+// raise passes error to the innermost try statement, and returns the frame which catches it.
+// Functions which error passes through are recorded in the stack of error.
+func (vm *VM) raise(err *object.Error, ip int) (*Frame, bool) {
+	frame := vm.frames[len(vm.frames)-1]
+	if !err.Pos.IsValid() {
+		err.Pos = frame.cl.Fn.Position(ip)
+	}
+	// generator doesn't know where it is resumed, so the instruction which resumes it is the call site
+	if n := len(err.Stack); n > 0 && !err.Stack[n-1].Pos.IsValid() {
+		err.Stack[n-1].Pos = frame.cl.Fn.Position(ip)
+	}
+
+	for {
+		if n := len(vm.handlers); n > 0 && vm.handlers[n-1].frame == len(vm.frames)-1 {
+			h := vm.handlers[n-1]
+			vm.handlers = vm.handlers[:n-1]
+
+			vm.sp = h.sp
+			frame.scope = h.scope
+			frame.ip = h.catchIP
+			vm.push(&object.Exception{Err: err})
+
+			return frame, true
+		}
+
+		if len(vm.frames) == 1 {
+			return nil, false
+		}
+
+		vm.frames = vm.frames[:len(vm.frames)-1]
+		caller := vm.frames[len(vm.frames)-1]
+		err.Stack = append(err.Stack, object.Frame{Function: frame.cl.Name(), Pos: caller.cl.Fn.CallSites[frame.callIP]})
+		frame = caller
+	}
+}